@@ -0,0 +1,53 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package main
+
+import "strings"
+
+// pathClaims tracks the config paths claimed by the apps as they're read, so
+// that each path is written by a single app: the first one to claim it.
+//
+// Only the claimed paths are kept, rather than a plan of every app, so that the
+// configs of each app can be generated as soon as it's read. As the apps are
+// claimed in the order of the data, no path is written differently depending on
+// the order that the apps are generated in.
+//
+// Paths are compared without case, as some file systems don't have case.
+//
+// A pathClaims isn't safe for concurrent use.
+type pathClaims struct {
+	claimed map[string]bool
+}
+
+// newPathClaims returns an empty pathClaims.
+func newPathClaims() *pathClaims {
+	return &pathClaims{claimed: make(map[string]bool)}
+}
+
+// Claim claims the given config paths for an app, and returns the keys of the
+// paths that it owns, as they weren't claimed by an earlier app, and the paths
+// that were.
+func (c *pathClaims) Claim(paths []string) (owned map[string]bool, taken []string) {
+	owned = make(map[string]bool)
+
+	for _, path := range paths {
+		key := pathClaimKey(path)
+
+		switch {
+		case owned[key]:
+			// Claimed more than once by the same app
+		case c.claimed[key]:
+			taken = append(taken, path)
+		default:
+			c.claimed[key] = true
+			owned[key] = true
+		}
+	}
+
+	return owned, taken
+}
+
+// pathClaimKey returns the key of a config path in a pathClaims.
+func pathClaimKey(path string) string {
+	return strings.ToLower(path)
+}
@@ -0,0 +1,116 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Rican7/psx-emu-conf/internal/data"
+	"github.com/Rican7/psx-emu-conf/internal/data/dat"
+)
+
+// runDAT exports the data as a libretro-database "metadat" DAT file of a
+// feature, either in full, or as the changes to an existing DAT file.
+func runDAT(name string, args []string) int {
+	flags := newFlagSet(name)
+
+	var featureNames []string
+	for _, feature := range dat.Features() {
+		featureNames = append(featureNames, string(feature))
+	}
+
+	pathToData := flags.String("data", defaultPathToData, "the path to the data file, or - for stdin")
+	featureName := flags.String("feature", "", fmt.Sprintf("the feature of the DAT file (%s)", strings.Join(featureNames, ", ")))
+	pathToExisting := flags.String("diff", "", "the path to an existing DAT file to apply only the real changes to")
+	pathToOutput := flags.String("output", "", "the path to write the DAT file to, instead of stdout")
+
+	flags.Parse(args)
+
+	feature, err := dat.ParseFeature(*featureName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flags.Usage()
+		return 2
+	}
+
+	apps, err := loadApps(*pathToData)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	validApps := apps[:0]
+	for _, app := range apps {
+		app.Normalize()
+
+		// Apps with only warnings are still exported
+		if err, ok := app.Validate().(*data.ValidationError); ok && err.HasErrors() {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+
+		validApps = append(validApps, app)
+	}
+
+	var datFile *dat.File
+
+	if *pathToExisting == "" {
+		datFile = dat.Export(validApps, feature)
+	} else {
+		existing, err := loadDATFile(*pathToExisting)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		var changes []dat.Change
+		datFile, changes = dat.Diff(existing, validApps, feature)
+
+		for _, change := range changes {
+			fmt.Fprintln(os.Stderr, change)
+		}
+
+		fmt.Fprintf(os.Stderr, "%d changes to %s\n", len(changes), *pathToExisting)
+	}
+
+	var output io.Writer = os.Stdout
+
+	if *pathToOutput != "" {
+		file, err := os.Create(*pathToOutput)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		defer file.Close()
+
+		output = file
+	}
+
+	if err := dat.Write(output, datFile); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+// loadDATFile loads the DAT file at the given path.
+func loadDATFile(path string) (*dat.File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	datFile, err := dat.Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	return datFile, nil
+}
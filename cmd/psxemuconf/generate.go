@@ -0,0 +1,557 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/Rican7/psx-emu-conf/internal/data"
+	"github.com/Rican7/psx-emu-conf/internal/emuconf"
+	"github.com/Rican7/psx-emu-conf/internal/emuconf/retroarch"
+	"github.com/Rican7/psx-emu-conf/internal/install"
+)
+
+// generateFlags defines the flags that control the generation of configs,
+// shared by the commands that generate them.
+type generateFlags struct {
+	pathToData           *string
+	strict               *bool
+	dryRun               *bool
+	overwrite            *bool
+	pathQuarantine       *string
+	pathToOverrides      *string
+	pathToProfile        *string
+	preferredDeviceNames *string
+	workers              *int
+}
+
+// addGenerateFlags defines the flags that control the generation of configs in
+// the given flag set.
+func addGenerateFlags(flags *flag.FlagSet) *generateFlags {
+	return &generateFlags{
+		pathToData:           flags.String("data", defaultPathToData, "the path to the data file, or - for stdin"),
+		strict:               flags.Bool("strict", false, "fail without writing anything if any app in the data is invalid"),
+		dryRun:               flags.Bool("dry-run", false, "print a summary and diffs of the changes to the config files, without writing anything"),
+		overwrite:            flags.Bool("overwrite", false, "replace existing config files, rather than merging into them"),
+		pathQuarantine:       flags.String("quarantine", "", "a path to write any invalid apps to, to be inspected later"),
+		pathToOverrides:      flags.String("overrides", "", "the path to a JSON file of manual overrides of apps and emulator options, keyed by serial code or title"),
+		pathToProfile:        flags.String("profile", "", "the path to a JSON profile of preferences that control the generated configs"),
+		preferredDeviceNames: flags.String("prefer", "", "a comma-separated list of input devices to prefer when an app supports them, such as \"negcon,mouse\" (overrides the profile)"),
+		workers:              flags.Int("workers", runtime.NumCPU(), "the number of apps to generate configs for in parallel"),
+	}
+}
+
+func runGenerate(name string, args []string) int {
+	flags := newFlagSet(name)
+
+	generateFlags := addGenerateFlags(flags)
+	pathToConfigFiles := flags.String("output", defaultPathToConfigFiles, "the path to the directory to write the config files to")
+
+	flags.Parse(args)
+
+	return generate(generateFlags, *pathToConfigFiles, nil)
+}
+
+// generate generates the configs into the given directory, as controlled by the
+// given flags, and returns the exit code.
+//
+// The files written are recorded in the given install manifest, if any.
+func generate(flags *generateFlags, pathToConfigFiles string, manifest *install.Manifest) int {
+	var profile emuconf.Profile
+	if *flags.pathToProfile != "" {
+		var err error
+		if profile, err = loadProfile(*flags.pathToProfile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	var overrides *emuconf.Overrides
+	if *flags.pathToOverrides != "" {
+		var err error
+		if overrides, err = loadOverrides(*flags.pathToOverrides); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	if *flags.preferredDeviceNames != "" {
+		profile.PreferredDevices = nil
+
+		for _, name := range strings.Split(*flags.preferredDeviceNames, ",") {
+			device, err := data.ParseInputDevice(name)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 2
+			}
+
+			profile.PreferredDevices = append(profile.PreferredDevices, device)
+		}
+	}
+
+	if *flags.workers < 1 {
+		fmt.Fprintln(os.Stderr, "the number of workers must be at least 1")
+		return 2
+	}
+
+	openData, releaseData, err := newDataSource(*flags.pathToData, *flags.strict)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	defer releaseData()
+
+	quarantine := newQuarantine(*flags.pathQuarantine)
+	defer quarantine.Close()
+
+	configurators := newConfigurators(profile)
+
+	var summary data.ValidationSummary
+
+	// Validate every app in a first pass in strict mode, before anything is
+	// written, so that nothing is written at all if any app is invalid
+	if *flags.strict {
+		err = forEachApp(openData, overrides, func(app data.App, override emuconf.Override) error {
+			_, err := validateApp(app, &summary, quarantine)
+
+			return err
+		})
+
+		fmt.Fprint(os.Stderr, summary.String())
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "reading %s: %s\n", *flags.pathToData, err)
+			return 1
+		}
+
+		if summary.NumInvalid > 0 {
+			fmt.Fprintln(os.Stderr, "invalid apps found in strict mode, so nothing was written")
+			quarantine.Close()
+			return 1
+		}
+	}
+
+	writer := newConfigWriter(pathToConfigFiles, *flags.overwrite, *flags.dryRun, os.Stdout, manifest)
+
+	pool := newConfigPool(*flags.workers, configurators, writer)
+
+	// Wait for the workers on every return, so that nothing is left partially
+	// written, or unrecorded in the install manifest
+	defer pool.Close()
+
+	claims := newPathClaims()
+
+	// Stream the apps, so that configs are generated as soon as each app is
+	// read, without holding all of the apps in memory
+	err = forEachApp(openData, overrides, func(app data.App, override emuconf.Override) error {
+		// Every app was already validated in strict mode
+		if !*flags.strict {
+			if valid, err := validateApp(app, &summary, quarantine); !valid || err != nil {
+				return err
+			}
+		}
+
+		// The paths of the configurators that don't accept the app are claimed
+		// too, as they're pruned of any stale settings
+		var paths []string
+		for _, configurator := range configurators {
+			// Errors are reported when the configs are written
+			if mainPath, altPaths, err := buildConfigPaths(app, configurator); err == nil {
+				paths = append(append(paths, mainPath), altPaths...)
+			}
+		}
+
+		owned, taken := claims.Claim(paths)
+		for _, path := range taken {
+			fmt.Fprintf(os.Stderr, "config path %q of %q is claimed by an earlier app, so only that app writes it\n", path, app.Title)
+		}
+
+		pool.Submit(app, override.Options, owned)
+
+		return nil
+	})
+
+	failures := pool.Close()
+
+	if !*flags.strict {
+		fmt.Fprint(os.Stderr, summary.String())
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reading %s: %s\n", *flags.pathToData, err)
+		return 1
+	}
+
+	if err := writer.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if *flags.dryRun {
+		fmt.Print(writer.Summary())
+	} else {
+		fmt.Fprint(os.Stderr, writer.Summary())
+	}
+
+	for _, key := range overrides.Unmatched() {
+		fmt.Fprintf(os.Stderr, "override %q didn't match any app\n", key)
+	}
+
+	if len(failures) > 0 {
+		fmt.Fprintf(os.Stderr, "%d config files failed:\n", len(failures))
+
+		for _, err := range failures {
+			fmt.Fprintf(os.Stderr, "  %s\n", err)
+		}
+	}
+
+	if err := quarantine.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if len(failures) > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// newDataSource returns a function that opens the data stream at the given
+// path, for each pass over the apps, and a function that releases the data.
+//
+// Stdin can only be read once, so it's spooled to a temporary file if it's
+// read more than once, as in strict mode.
+func newDataSource(path string, strict bool) (open func() (io.ReadCloser, error), release func(), err error) {
+	if path != "-" || !strict {
+		return func() (io.ReadCloser, error) { return openDataStream(path) }, func() {}, nil
+	}
+
+	spool, err := ioutil.TempFile("", "psxemuconf-data-*.ndjson")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	release = func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}
+
+	if _, err := io.Copy(spool, os.Stdin); err != nil {
+		release()
+		return nil, nil, fmt.Errorf("reading stdin: %w", err)
+	}
+
+	return func() (io.ReadCloser, error) {
+		// Each pass reads the whole spool, so it's only closed once released
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+
+		return ioutil.NopCloser(spool), nil
+	}, release, nil
+}
+
+// validateApp validates the given app, reporting any problems, and records it
+// in the given summary. Invalid apps are written to the given quarantine.
+//
+// It returns true if the app is valid.
+func validateApp(app data.App, summary *data.ValidationSummary, quarantine *quarantine) (bool, error) {
+	validationErr := app.Validate()
+	if validationErr != nil {
+		fmt.Fprintln(os.Stderr, validationErr)
+	}
+
+	if !summary.Record(app, validationErr) {
+		return false, quarantine.Write(app)
+	}
+
+	return true, nil
+}
+
+// forEachApp streams the apps of the data, calling the given function for each
+// app, once it's normalized and its override is applied.
+func forEachApp(openData func() (io.ReadCloser, error), overrides *emuconf.Overrides, fn func(app data.App, override emuconf.Override) error) error {
+	dataStream, err := openData()
+	if err != nil {
+		return err
+	}
+
+	defer dataStream.Close()
+
+	reader := data.NewAppReader(dataStream)
+	for reader.Next() {
+		app := reader.App()
+		app.Normalize()
+
+		override, hasOverride := overrides.Lookup(app)
+		if hasOverride {
+			var err error
+			if app, err = override.Apply(app); err != nil {
+				return fmt.Errorf("applying the override of %q: %w", app.Title, err)
+			}
+
+			// Normalize again, as the overridden fields may not be
+			app.Normalize()
+		}
+
+		// Add the variations of the title, to locate the configs by
+		app.ExpandTitleVariations()
+
+		if err := fn(app, override); err != nil {
+			return err
+		}
+	}
+
+	return reader.Err()
+}
+
+// newConfigurators returns the emulation configurators, with the given profile.
+func newConfigurators(profile emuconf.Profile) []emuconf.Configurator {
+	return []emuconf.Configurator{
+		retroarch.NewPCSXReARMed(profile),
+		retroarch.NewBeetlePSX(profile),
+		retroarch.NewBeetlePSXHW(profile),
+		retroarch.NewPCSXReARMedDeviceOverride(profile),
+		retroarch.NewBeetlePSXDeviceOverride(profile),
+		retroarch.NewBeetlePSXHWDeviceOverride(profile),
+	}
+}
+
+// loadProfile loads the profile at the given path.
+func loadProfile(path string) (emuconf.Profile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return emuconf.Profile{}, err
+	}
+
+	defer file.Close()
+
+	profile, err := emuconf.LoadProfile(file)
+	if err != nil {
+		return emuconf.Profile{}, fmt.Errorf("loading profile %s: %w", path, err)
+	}
+
+	return profile, nil
+}
+
+// loadOverrides loads the overrides at the given path.
+func loadOverrides(path string) (*emuconf.Overrides, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	overrides, err := emuconf.LoadOverrides(file)
+	if err != nil {
+		return nil, fmt.Errorf("loading overrides %s: %w", path, err)
+	}
+
+	return overrides, nil
+}
+
+// writeConfigs writes the config files of each of the given configurators for
+// the given app, with the given writer, with the given raw emulator options
+// overriding those of the configurators that own them.
+//
+// Only the config paths that the given function reports the app as owning are
+// written. The owned config paths of the configurators that don't accept the
+// app are pruned of the settings that they manage instead, so that none are
+// left from earlier runs.
+//
+// Every config file is attempted, and the errors of any that failed are
+// returned.
+func writeConfigs(app data.App, options map[string]string, configurators []emuconf.Configurator, writer *configWriter, owns func(path string) bool) []error {
+	var errs []error
+
+	ownedOptions := make(map[string]bool)
+
+	for _, configurator := range configurators {
+		if filter, ok := configurator.(emuconf.Filter); ok && !filter.Accepts(app) {
+			errs = append(errs, pruneConfigs(app, configurator, writer, owns)...)
+			continue
+		}
+
+		// Own the options, even if no config is written, as they belong
+		configuratorOptions := ownOptions(configurator, options)
+		for key := range configuratorOptions {
+			ownedOptions[key] = true
+		}
+
+		mainConfigFilePath, altConfigFilePaths, err := buildConfigPaths(app, configurator)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("configuring %q for %s: %w", app.Title, configurator.EmulatorName(), err))
+			continue
+		}
+
+		configFilePaths := ownedConfigPaths(append([]string{mainConfigFilePath}, altConfigFilePaths...), owns)
+
+		if len(configFilePaths) == 0 {
+			continue
+		}
+
+		var generated bytes.Buffer
+
+		if len(configuratorOptions) > 0 {
+			err = configurator.(emuconf.OptionsOverrider).ConfigureWithOptions(&generated, app, configuratorOptions)
+		} else {
+			err = configurator.Configure(&generated, app)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("configuring %q for %s: %w", app.Title, configurator.EmulatorName(), err))
+			continue
+		}
+
+		merger, _ := configurator.(emuconf.Merger)
+
+		for _, configFilePath := range configFilePaths {
+			if err := writer.Write(configFilePath, generated.Bytes(), merger); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for key := range options {
+		if !ownedOptions[key] {
+			fmt.Fprintf(os.Stderr, "override option %q of %q doesn't belong to any emulator\n", key, app.Title)
+		}
+	}
+
+	return errs
+}
+
+// pruneConfigs prunes the config files of the given configurator for the given
+// app, which it doesn't accept, of the settings that it manages, with the given
+// writer, if it's a pruner.
+//
+// Only the config paths that the given function reports the app as owning are
+// pruned.
+func pruneConfigs(app data.App, configurator emuconf.Configurator, writer *configWriter, owns func(path string) bool) []error {
+	pruner, ok := configurator.(emuconf.Pruner)
+	if !ok {
+		return nil
+	}
+
+	mainConfigFilePath, altConfigFilePaths, err := buildConfigPaths(app, configurator)
+	if err != nil {
+		return []error{fmt.Errorf("configuring %q for %s: %w", app.Title, configurator.EmulatorName(), err)}
+	}
+
+	var errs []error
+
+	for _, configFilePath := range ownedConfigPaths(append([]string{mainConfigFilePath}, altConfigFilePaths...), owns) {
+		if err := writer.Prune(configFilePath, pruner); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// ownedConfigPaths returns the given config paths that the given function
+// reports as owned, each once, even if the paths differ only by case.
+func ownedConfigPaths(configFilePaths []string, owns func(path string) bool) []string {
+	var owned []string
+	seen := make(map[string]bool)
+
+	for _, configFilePath := range configFilePaths {
+		if key := pathClaimKey(configFilePath); owns(configFilePath) && !seen[key] {
+			seen[key] = true
+			owned = append(owned, configFilePath)
+		}
+	}
+
+	return owned
+}
+
+// ownOptions returns the options that belong to the given configurator, if it
+// allows options to be overridden.
+func ownOptions(configurator emuconf.Configurator, options map[string]string) map[string]string {
+	overrider, ok := configurator.(emuconf.OptionsOverrider)
+	if !ok {
+		return nil
+	}
+
+	owned := make(map[string]string)
+
+	for key, value := range options {
+		if overrider.OwnsOption(key) {
+			owned[key] = value
+		}
+	}
+
+	return owned
+}
+
+// buildConfigPaths returns the main config path of the given app for the given
+// configurator, and any alternative config paths.
+func buildConfigPaths(app data.App, configurator emuconf.Configurator) (string, []string, error) {
+	mainPath, err := buildConfigPath(app, configurator)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var altPaths []string
+
+	if altLocator, ok := configurator.(emuconf.AlternativesLocator); ok {
+		altPaths = buildAltConfigPaths(app, altLocator)
+	}
+
+	return mainPath, altPaths, nil
+}
+
+func buildConfigPath(app data.App, configurator emuconf.Configurator) (string, error) {
+	var confPath string
+
+	if locator, ok := configurator.(emuconf.Locator); ok {
+		confPath = locator.Path(app)
+	}
+
+	if confPath == "" {
+		switch {
+		case app.SerialCode != "":
+			confPath = app.SerialCode
+		case app.Title != "":
+			confPath = app.Title
+		}
+	}
+
+	confPath = filepath.Clean(confPath)
+
+	base := filepath.Base(confPath)
+	if base == "" || base == "." || base == string(filepath.Separator) {
+		return "", errors.New("incomplete file path")
+	}
+
+	return confPath, nil
+}
+
+func buildAltConfigPaths(app data.App, altLocator emuconf.AlternativesLocator) []string {
+	var altConfPaths []string
+
+	for _, altConfPath := range altLocator.AlternativePaths(app) {
+		altConfPath = filepath.Clean(altConfPath)
+
+		base := filepath.Base(altConfPath)
+		if base == "" || base == "." || base == string(filepath.Separator) {
+			// Ignore this path, as its invalid
+			continue
+		}
+
+		altConfPaths = append(altConfPaths, altConfPath)
+	}
+
+	return altConfPaths
+}
@@ -0,0 +1,173 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Rican7/psx-emu-conf/internal/emuconf/retroarch"
+	"github.com/Rican7/psx-emu-conf/internal/install"
+)
+
+// retroArchFlags defines the flags that locate a RetroArch installation, shared
+// by the commands that install into one.
+type retroArchFlags struct {
+	pathToMainConfig  *string
+	pathToApplication *string
+}
+
+// addRetroArchFlags defines the flags that locate a RetroArch installation in
+// the given flag set.
+func addRetroArchFlags(flags *flag.FlagSet) *retroArchFlags {
+	return &retroArchFlags{
+		pathToMainConfig:  flags.String("retroarch-config", "", "the path to the main RetroArch config file (retroarch.cfg) of the installation (required)"),
+		pathToApplication: flags.String("retroarch-dir", "", "the path to the RetroArch application directory, that the \":\" path token refers to (defaults to the directory of the main config file)"),
+	}
+}
+
+func runInstall(name string, args []string) int {
+	flags := newFlagSet(name)
+
+	generateFlags := addGenerateFlags(flags)
+	retroArchFlags := addRetroArchFlags(flags)
+
+	flags.Parse(args)
+
+	if *retroArchFlags.pathToMainConfig == "" {
+		fmt.Fprintln(os.Stderr, "a main RetroArch config file is required")
+		flags.Usage()
+		return 2
+	}
+
+	configDir, err := resolveRetroArchConfigDirectory(*retroArchFlags.pathToMainConfig, *retroArchFlags.pathToApplication)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	manifest, err := install.OpenManifest(configDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "installing into %s\n", configDir)
+
+	code := generate(generateFlags, configDir, manifest)
+
+	// Save the manifest even after a failure, to track what was written
+	if !*generateFlags.dryRun {
+		if err := manifest.Save(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	return code
+}
+
+func runUninstall(name string, args []string) int {
+	flags := newFlagSet(name)
+
+	retroArchFlags := addRetroArchFlags(flags)
+
+	flags.Parse(args)
+
+	if *retroArchFlags.pathToMainConfig == "" {
+		fmt.Fprintln(os.Stderr, "a main RetroArch config file is required")
+		flags.Usage()
+		return 2
+	}
+
+	configDir, err := resolveRetroArchConfigDirectory(*retroArchFlags.pathToMainConfig, *retroArchFlags.pathToApplication)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	manifest, err := install.OpenManifest(configDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "uninstalling from %s\n", configDir)
+
+	results, err := manifest.Uninstall()
+
+	counts := make(map[install.Action]int)
+
+	for _, result := range results {
+		counts[result.Action]++
+
+		// Only report the files that need the attention of the user
+		if result.Action == install.ActionKept || result.Action == install.ActionMissing {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", result.File.Path, result.Action)
+		}
+	}
+
+	fmt.Fprintf(
+		os.Stderr,
+		"uninstalled %d config files: %d removed, %d restored, %d kept, %d missing\n",
+		len(results),
+		counts[install.ActionRemoved],
+		counts[install.ActionRestored],
+		counts[install.ActionKept],
+		counts[install.ActionMissing],
+	)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if remaining := len(manifest.Files()); remaining > 0 {
+		fmt.Fprintf(os.Stderr, "%d config files are still tracked in %s\n", remaining, filepath.Join(configDir, install.DirName))
+	}
+
+	return 0
+}
+
+// resolveRetroArchConfigDirectory resolves the directory where RetroArch stores
+// configurations, from the main RetroArch config file at the given path.
+//
+// The ":" path token refers to the given application directory, or to the
+// directory of the main config file, if empty, as in a portable installation.
+func resolveRetroArchConfigDirectory(pathToMainConfig string, applicationDir string) (string, error) {
+	file, err := os.Open(pathToMainConfig)
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	mainConfig, err := retroarch.ParseConfig(file)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", pathToMainConfig, err)
+	}
+
+	mainConfigDir, err := filepath.Abs(filepath.Dir(pathToMainConfig))
+	if err != nil {
+		return "", err
+	}
+
+	if applicationDir == "" {
+		applicationDir = mainConfigDir
+	}
+
+	configDir, err := retroarch.ConfigDirectory(mainConfig, mainConfigDir, applicationDir)
+	if err != nil {
+		return "", fmt.Errorf("resolving the config directory of %s: %w", pathToMainConfig, err)
+	}
+
+	// Make sure that the directory is plausible, rather than creating a stray
+	// tree of directories from a bad path
+	if _, err := os.Stat(filepath.Dir(configDir)); err != nil {
+		return "", fmt.Errorf("resolving the config directory of %s: %w", pathToMainConfig, err)
+	}
+
+	return configDir, nil
+}
@@ -0,0 +1,57 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Rican7/psx-emu-conf/internal/data"
+)
+
+// runLint loads a data file, normalizes and validates every app within it, and
+// checks the rules that apply across apps, reporting every problem found.
+//
+// A non-zero exit status is returned if any app is invalid, or if any app has
+// warnings in strict mode.
+func runLint(name string, args []string) int {
+	flags := newFlagSet(name)
+
+	strict := flags.Bool("strict", false, "fail on warnings, as well as errors")
+
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s [flags] [data-file]\n", flags.Name())
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(args)
+
+	pathToData := defaultPathToData
+	if flags.NArg() > 0 {
+		pathToData = flags.Arg(0)
+	}
+
+	apps, err := loadApps(pathToData)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	for i := range apps {
+		apps[i].Normalize()
+	}
+
+	report := data.LintApps(apps)
+
+	for _, err := range report.Errors {
+		fmt.Println(err)
+	}
+
+	fmt.Print(report.Summary())
+
+	if len(report.Invalid) > 0 || (*strict && len(report.Errors) > 0) {
+		return 1
+	}
+
+	return 0
+}
@@ -3,15 +3,14 @@
 package main
 
 import (
-	"encoding/json"
-	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 
 	"github.com/Rican7/psx-emu-conf/internal/data"
-	"github.com/Rican7/psx-emu-conf/internal/emuconf"
-	"github.com/Rican7/psx-emu-conf/internal/emuconf/retroarch"
 )
 
 const (
@@ -19,137 +18,225 @@ const (
 	defaultPathToConfigFiles = "_configs"
 )
 
+// command defines a sub-command of the program.
+type command struct {
+	name        string
+	description string
+	run         func(name string, args []string) int
+}
+
+// The default command, run when no command name is given.
+var defaultCommand = command{
+	name:        "generate",
+	description: "generate emulator configs from the data (default)",
+	run:         runGenerate,
+}
+
+// The available commands.
+//
+// NOTE: This is populated in init, to avoid an initialization cycle with the
+// usage function, which references the commands.
+var commands []command
+
+func init() {
+	commands = []command{
+		defaultCommand,
+		{
+			name:        "install",
+			description: "generate emulator configs into a RetroArch installation",
+			run:         runInstall,
+		},
+		{
+			name:        "uninstall",
+			description: "remove the installed configs from a RetroArch installation, restoring the files they replaced",
+			run:         runUninstall,
+		},
+		{
+			name:        "lint",
+			description: "check the data for problems, such as invalid or duplicate apps",
+			run:         runLint,
+		},
+		{
+			name:        "schema",
+			description: "print the JSON Schema of the data format",
+			run:         runSchema,
+		},
+		{
+			name:        "migrate",
+			description: "rewrite data files in the current data format",
+			run:         runMigrate,
+		},
+		{
+			name:        "options",
+			description: "print the emulator options of apps, in a chosen format",
+			run:         runOptions,
+		},
+		{
+			name:        "dat",
+			description: "export the data as a libretro-database DAT file",
+			run:         runDAT,
+		},
+	}
+}
+
 // TODO:
 //
 //  - Abstract and organize a bit
-//  - Handle errors far better... os.Stderr?
-//  - Add flags/options
-//  - Get data path from flags
-//  - Allow for piping data via stdin
-//  - Get output path from flags
 func main() {
-	dataStream, err := os.Open(defaultPathToData)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	cmd := defaultCommand
+	args := os.Args[1:]
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "help", "-h", "-help", "--help":
+			usage()
+			os.Exit(0)
+		}
+
+		for _, c := range commands {
+			if c.name == args[0] {
+				cmd = c
+				args = args[1:]
+				break
+			}
+		}
 	}
 
-	defer dataStream.Close()
+	os.Exit(cmd.run(cmd.name, args))
+}
 
-	var apps []data.App
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [command] [flags]\n\nCommands:\n", programName())
 
-	decoder := json.NewDecoder(dataStream)
-	if err := decoder.Decode(&apps); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.description)
 	}
 
-	// Close the open stream now that its been decoded, to save on file handles
-	// (we're going to need ALL the file handles that we can get... 😅)
-	dataStream.Close()
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for the flags of a command.\n", programName())
+}
 
-	for i := range apps {
-		apps[i].Normalize()
+// newFlagSet returns a new flag.FlagSet for the named command.
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet(fmt.Sprintf("%s %s", programName(), name), flag.ExitOnError)
+}
 
-		if err := apps[i].Validate(); err != nil {
-			fmt.Println(err)
-			continue
-		}
+// openDataStream opens the data file at the given path for reading, or stdin if
+// the path is "-".
+func openDataStream(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return ioutil.NopCloser(os.Stdin), nil
 	}
 
-	configurators := []emuconf.Configurator{
-		retroarch.NewPCSXReARMed(),
-		retroarch.NewBeetlePSX(),
-		retroarch.NewBeetlePSXHW(),
+	return os.Open(path)
+}
+
+// loadDataFile loads the data file at the given path.
+func loadDataFile(path string) (*data.File, error) {
+	dataStream, err := openDataStream(path)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, app := range apps {
-		for _, configurator := range configurators {
-			var configFilePaths []string
+	defer dataStream.Close()
 
-			mainConfigFilePath, err := buildConfigPath(app, configurator)
-			if err != nil {
-				fmt.Println(err)
-				continue
-			}
+	dataFile, err := data.Decode(dataStream)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
 
-			configFilePaths = append(configFilePaths, mainConfigFilePath)
+	return dataFile, nil
+}
 
-			if altLocator, ok := configurator.(emuconf.AlternativesLocator); ok {
-				altConfigFilePaths := buildAltConfigPaths(app, altLocator)
+// loadApps loads the apps from the data stream at the given path, in any of the
+// supported formats.
+func loadApps(path string) ([]data.App, error) {
+	dataStream, err := openDataStream(path)
+	if err != nil {
+		return nil, err
+	}
 
-				configFilePaths = append(configFilePaths, altConfigFilePaths...)
-			}
+	defer dataStream.Close()
 
-			for _, configFilePath := range configFilePaths {
-				configFilePath = filepath.Join(defaultPathToConfigFiles, configFilePath)
-				configFileDir := filepath.Dir(configFilePath)
+	var apps []data.App
 
-				err = os.MkdirAll(configFileDir, 0777)
-				if err != nil {
-					fmt.Println(err)
-					continue
-				}
+	reader := data.NewAppReader(dataStream)
+	for reader.Next() {
+		apps = append(apps, reader.App())
+	}
 
-				file, err := os.Create(configFilePath)
-				if err != nil {
-					fmt.Println(err)
-					continue
-				}
+	if err := reader.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
 
-				defer file.Close()
+	return apps, nil
+}
 
-				err = configurator.Configure(file, app)
-				if err != nil {
-					fmt.Println(err)
-				}
+func writeDataFile(path string, dataFile *data.File) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
 
-				file.Close()
-			}
-		}
+	defer file.Close()
+
+	if err := data.Encode(file, dataFile); err != nil {
+		return err
 	}
+
+	return file.Close()
 }
 
-func buildConfigPath(app data.App, configurator emuconf.Configurator) (string, error) {
-	var confPath string
+func programName() string {
+	return filepath.Base(os.Args[0])
+}
 
-	if locator, ok := configurator.(emuconf.Locator); ok {
-		confPath = locator.Path(app)
-	}
+// quarantine writes invalid apps to a data file, lazily creating the file only
+// once the first app is written.
+type quarantine struct {
+	path string
 
-	if confPath == "" {
-		switch {
-		case app.SerialCode != "":
-			confPath = app.SerialCode
-		case app.Title != "":
-			confPath = app.Title
-		}
+	file   *os.File
+	writer *data.AppWriter
+}
+
+// newQuarantine returns a quarantine that writes to the given path. If the path
+// is empty, the apps are discarded.
+func newQuarantine(path string) *quarantine {
+	return &quarantine{path: path}
+}
+
+func (q *quarantine) Write(app data.App) error {
+	if q.path == "" {
+		return nil
 	}
 
-	confPath = filepath.Clean(confPath)
+	if q.writer == nil {
+		file, err := os.Create(q.path)
+		if err != nil {
+			return err
+		}
 
-	base := filepath.Base(confPath)
-	if base == "" || base == "." || base == string(filepath.Separator) {
-		return "", errors.New("incomplete file path")
+		q.file = file
+		q.writer = data.NewAppWriter(file, data.FormatJSON, data.File{})
 	}
 
-	return confPath, nil
+	return q.writer.Write(app)
 }
 
-func buildAltConfigPaths(app data.App, altLocator emuconf.AlternativesLocator) []string {
-	var altConfPaths []string
-
-	for _, altConfPath := range altLocator.AlternativePaths(app) {
-		altConfPath = filepath.Clean(altConfPath)
+// Close completes the quarantine file, if one was created. It's safe to call
+// more than once.
+func (q *quarantine) Close() error {
+	if q.writer == nil {
+		return nil
+	}
 
-		base := filepath.Base(altConfPath)
-		if base == "" || base == "." || base == string(filepath.Separator) {
-			// Output the error, but ignore this path, as its invalid
-			fmt.Println("incomplete alt file path")
-		}
+	writer, file := q.writer, q.file
+	q.writer, q.file = nil, nil
 
-		altConfPaths = append(altConfPaths, altConfPath)
+	if err := writer.Close(); err != nil {
+		file.Close()
+		return err
 	}
 
-	return altConfPaths
+	return file.Close()
 }
@@ -0,0 +1,50 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// runMigrate rewrites data files in the current data format, so that files
+// written in a legacy format (ex: a bare array, or with integer support levels)
+// are upgraded.
+//
+// The data itself isn't normalized or validated, only migrated and re-encoded.
+func runMigrate(name string, args []string) int {
+	flags := newFlagSet(name)
+
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s [data-file...]\n", flags.Name())
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(args)
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{defaultPathToData}
+	}
+
+	exitCode := 0
+
+	for _, path := range paths {
+		dataFile, err := loadDataFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 1
+			continue
+		}
+
+		if err := writeDataFile(path, dataFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 1
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "migrated %s\n", path)
+	}
+
+	return exitCode
+}
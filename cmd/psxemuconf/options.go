@@ -0,0 +1,180 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Rican7/psx-emu-conf/internal/data"
+	"github.com/Rican7/psx-emu-conf/internal/data/normalize"
+	"github.com/Rican7/psx-emu-conf/internal/emuconf"
+	"github.com/Rican7/psx-emu-conf/internal/emuconf/encoding"
+)
+
+// runOptions prints the emulator options of the apps matching the given serial
+// codes or titles, so that they can be inspected without generating configs.
+//
+// The options of each emulator are preceded by a header on stderr, so that the
+// options on stdout remain in a valid format. Formats that can group the options
+// of several emulators print a single document for each app instead.
+func runOptions(name string, args []string) int {
+	flags := newFlagSet(name)
+
+	pathToData := flags.String("data", defaultPathToData, "the path to the data file, or - for stdin")
+	formatName := flags.String("format", "retroarch", fmt.Sprintf("the format to print the options in (%s)", strings.Join(encoding.Names(), ", ")))
+	pathToOverrides := flags.String("overrides", "", "the path to a JSON file of manual overrides of apps and emulator options, keyed by serial code or title")
+	pathToProfile := flags.String("profile", "", "the path to a JSON profile of preferences that control the options")
+
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s [flags] <serial-code|title>...\n", flags.Name())
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	encoder, err := encoding.ForName(*formatName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	var profile emuconf.Profile
+	if *pathToProfile != "" {
+		if profile, err = loadProfile(*pathToProfile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	var overrides *emuconf.Overrides
+	if *pathToOverrides != "" {
+		if overrides, err = loadOverrides(*pathToOverrides); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	apps, err := loadApps(*pathToData)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	wanted := make(map[string]bool)
+	for _, arg := range flags.Args() {
+		wanted[arg] = true
+
+		if serialCode := normalize.SerialCode(arg); serialCode != "" {
+			wanted[serialCode] = true
+		}
+	}
+
+	configurators := newConfigurators(profile)
+	found := false
+
+	for _, app := range apps {
+		app.Normalize()
+
+		// Match re-releases by the serial code of their original release too
+		if !wanted[app.SerialCode] && !wanted[normalize.BaseSerialCode(app.SerialCode)] && !wanted[app.Title] {
+			continue
+		}
+
+		found = true
+
+		override, hasOverride := overrides.Lookup(app)
+		if hasOverride {
+			if app, err = override.Apply(app); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+
+			app.Normalize()
+		}
+
+		if err := printOptions(app, override.Options, configurators, encoder); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	if !found {
+		fmt.Fprintln(os.Stderr, "no matching apps found")
+		return 1
+	}
+
+	return 0
+}
+
+// printOptions prints the options of each of the given configurators for the
+// given app, with the given raw options overriding those that they own.
+func printOptions(app data.App, rawOptions map[string]string, configurators []emuconf.Configurator, encoder encoding.Encoder) error {
+	groupEncoder, grouped := encoder.(encoding.GroupEncoder)
+
+	var groups []encoding.Group
+
+	for _, configurator := range configurators {
+		optionsConfigurator, ok := configurator.(emuconf.OptionsConfigurator)
+		if !ok {
+			continue
+		}
+
+		if filter, ok := configurator.(emuconf.Filter); ok && !filter.Accepts(app) {
+			continue
+		}
+
+		options, err := optionsConfigurator.Options(app)
+		if err != nil {
+			return err
+		}
+
+		ownedOptions := ownOptions(configurator, rawOptions)
+
+		var keys []string
+		for key := range ownedOptions {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			options.Set(key, ownedOptions[key])
+		}
+
+		if grouped {
+			groups = append(groups, encoding.Group{Name: configurator.EmulatorName(), Options: options})
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "==> %s: %s <==\n", configurator.EmulatorName(), describeApp(app))
+
+		if err := encoder.Encode(os.Stdout, options); err != nil {
+			return err
+		}
+	}
+
+	if !grouped {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "==> %s <==\n", describeApp(app))
+
+	return groupEncoder.EncodeGroups(os.Stdout, groups)
+}
+
+// describeApp returns a short, human-readable identification of an app.
+func describeApp(app data.App) string {
+	if app.SerialCode == "" {
+		return app.Title
+	}
+
+	return fmt.Sprintf("%s (%s)", app.Title, app.SerialCode)
+}
@@ -0,0 +1,97 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package main
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/Rican7/psx-emu-conf/internal/data"
+	"github.com/Rican7/psx-emu-conf/internal/emuconf"
+)
+
+// configJob defines the generation of the configs of an app.
+type configJob struct {
+	app     data.App
+	options map[string]string
+
+	// The keys of the config paths that the app owns. See pathClaims.
+	owned map[string]bool
+}
+
+// configPool generates the configs of apps with a pool of workers, in
+// parallel, writing them with a shared writer.
+type configPool struct {
+	jobs chan configJob
+	wait sync.WaitGroup
+
+	mutex    sync.Mutex
+	closed   bool
+	failures []error
+}
+
+// newConfigPool returns a configPool, with the given number of workers, that
+// generates the configs of the given configurators, at the paths that each app
+// owns.
+func newConfigPool(workers int, configurators []emuconf.Configurator, writer *configWriter) *configPool {
+	pool := &configPool{
+		// Buffer a job for each worker, so that reading the next app doesn't
+		// wait on a worker
+		jobs: make(chan configJob, workers),
+	}
+
+	for i := 0; i < workers; i++ {
+		pool.wait.Add(1)
+
+		go func() {
+			defer pool.wait.Done()
+
+			for job := range pool.jobs {
+				owns := func(path string) bool {
+					return job.owned[pathClaimKey(path)]
+				}
+
+				errs := writeConfigs(job.app, job.options, configurators, writer, owns)
+
+				if len(errs) > 0 {
+					pool.mutex.Lock()
+					pool.failures = append(pool.failures, errs...)
+					pool.mutex.Unlock()
+				}
+			}
+		}()
+	}
+
+	return pool
+}
+
+// Submit submits the generation of the configs of the given app, with the given
+// raw emulator options, at the config paths of the given keys that it owns,
+// blocking while every worker is busy.
+func (p *configPool) Submit(app data.App, options map[string]string, owned map[string]bool) {
+	p.jobs <- configJob{app: app, options: options, owned: owned}
+}
+
+// Close waits for the submitted jobs to complete, and returns the errors of any
+// configs that failed, in order of their messages. It's safe to call more than
+// once.
+func (p *configPool) Close() []error {
+	p.mutex.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.mutex.Unlock()
+
+	p.wait.Wait()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// Sort, as the workers fail in any order
+	sort.Slice(p.failures, func(i, j int) bool {
+		return p.failures[i].Error() < p.failures[j].Error()
+	})
+
+	return p.failures
+}
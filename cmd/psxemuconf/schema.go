@@ -0,0 +1,28 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Rican7/psx-emu-conf/internal/data"
+)
+
+// runSchema writes the JSON Schema of the data format to stdout.
+func runSchema(name string, args []string) int {
+	flags := newFlagSet(name)
+
+	flags.Parse(args)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(data.JSONSchema()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
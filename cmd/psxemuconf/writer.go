@@ -0,0 +1,320 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/Rican7/psx-emu-conf/internal/atomicfile"
+	"github.com/Rican7/psx-emu-conf/internal/diff"
+	"github.com/Rican7/psx-emu-conf/internal/emuconf"
+	"github.com/Rican7/psx-emu-conf/internal/install"
+)
+
+// maxOpenFiles defines the maximum number of files that a configWriter keeps
+// open at once, no matter how many goroutines write with it, so that the limit
+// of open files of the process is never reached.
+const maxOpenFiles = 16
+
+// configWriter writes generated config files into a directory, merging them
+// into any existing config files, and keeps count of the changes.
+//
+// In a dry run, nothing is written, and a unified diff of each change is
+// printed instead, once the writer is closed.
+//
+// If the writer has an install manifest, the files written are recorded in it,
+// and the files that they replace are backed up. Installed files that were
+// modified since they were installed are skipped.
+//
+// Installed config files can be pruned of the settings of configurators that no
+// longer configure an app, and are removed once nothing else is left in them.
+//
+// A configWriter is safe for concurrent use, but each path must only be written
+// once, as the order of concurrent writes isn't defined. See pathClaims.
+type configWriter struct {
+	root      string
+	overwrite bool
+	dryRun    bool
+	diffs     io.Writer
+	manifest  *install.Manifest
+
+	// A semaphore of the open files.
+	openFiles chan struct{}
+
+	mutex        sync.Mutex
+	pendingDiffs map[string]string
+	numCreated   int
+	numModified  int
+	numRemoved   int
+	numUnchanged int
+	numSkipped   int
+}
+
+// newConfigWriter returns a configWriter that writes into the given directory.
+//
+// Existing config files are replaced if overwrite is true, rather than merged
+// into, and in a dry run the diffs are printed to the given writer. The install
+// manifest is optional.
+func newConfigWriter(root string, overwrite bool, dryRun bool, diffs io.Writer, manifest *install.Manifest) *configWriter {
+	return &configWriter{
+		root:      root,
+		overwrite: overwrite,
+		dryRun:    dryRun,
+		diffs:     diffs,
+		manifest:  manifest,
+
+		openFiles:    make(chan struct{}, maxOpenFiles),
+		pendingDiffs: make(map[string]string),
+	}
+}
+
+// Write writes the generated content of the config file at the given path,
+// relative to the directory of the writer. If a merger is given, the content
+// is merged into any existing config file.
+func (w *configWriter) Write(relPath string, generated []byte, merger emuconf.Merger) error {
+	path := filepath.Join(w.root, relPath)
+
+	// Only one file is open at a time within a write, as each is closed before
+	// the next is opened
+	w.openFiles <- struct{}{}
+	defer func() { <-w.openFiles }()
+
+	existing, err := ioutil.ReadFile(path)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	content := generated
+
+	// Merge into any existing config, to keep the settings set by hand, and
+	// only prune the stale settings of configs that were written by this tool
+	if exists && merger != nil && !w.overwrite {
+		var merged bytes.Buffer
+
+		prune := w.installed(relPath, existing)
+
+		if err := merger.Merge(&merged, bytes.NewReader(existing), bytes.NewReader(generated), prune); err != nil {
+			return fmt.Errorf("merging into %s: %w", path, err)
+		}
+
+		content = merged.Bytes()
+	}
+
+	if exists && bytes.Equal(existing, content) {
+		w.count(&w.numUnchanged)
+
+		// Leave unchanged files untouched
+		return nil
+	}
+
+	// Leave installed files that were modified by hand alone, so that they can
+	// be left alone on uninstall too
+	if w.manifest != nil && exists && w.manifest.Modified(relPath, existing) {
+		w.count(&w.numSkipped)
+
+		return nil
+	}
+
+	if w.dryRun {
+		existingLabel := path
+		if !exists {
+			existingLabel = os.DevNull
+		}
+
+		w.addDiff(path, existingLabel, path, existing, content)
+	} else if err := w.writeFile(relPath, path, existing, exists, content); err != nil {
+		return err
+	}
+
+	if exists {
+		w.count(&w.numModified)
+	} else {
+		w.count(&w.numCreated)
+	}
+
+	return nil
+}
+
+// Prune removes the settings managed by the given pruner from the existing
+// config file at the given path, relative to the directory of the writer, for
+// when its configurator no longer configures the app. The file is removed if
+// nothing else is left in it.
+//
+// Only config files that were installed, and not modified since, are pruned,
+// so that nothing is pruned without an install manifest. Removed files are
+// restored from their backups.
+func (w *configWriter) Prune(relPath string, pruner emuconf.Pruner) error {
+	path := filepath.Join(w.root, relPath)
+
+	w.openFiles <- struct{}{}
+	defer func() { <-w.openFiles }()
+
+	existing, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if w.manifest == nil || !w.manifest.Tracked(relPath) {
+		return nil
+	}
+
+	if w.manifest.Modified(relPath, existing) {
+		w.count(&w.numSkipped)
+
+		return nil
+	}
+
+	var pruned bytes.Buffer
+	if err := pruner.Prune(&pruned, bytes.NewReader(existing)); err != nil {
+		return fmt.Errorf("pruning %s: %w", path, err)
+	}
+
+	content := pruned.Bytes()
+
+	if bytes.Equal(existing, content) {
+		return nil
+	}
+
+	// Keep the files with anything else left in them
+	if len(bytes.TrimSpace(content)) > 0 {
+		if w.dryRun {
+			w.addDiff(path, path, path, existing, content)
+		} else if err := w.writeFile(relPath, path, existing, true, content); err != nil {
+			return err
+		}
+
+		w.count(&w.numModified)
+
+		return nil
+	}
+
+	// Uninstall the file, to restore the file that it replaced, if any
+	if w.dryRun {
+		w.addDiff(path, path, os.DevNull, existing, nil)
+	} else if _, err := w.manifest.UninstallFile(relPath); err != nil {
+		return fmt.Errorf("removing %s: %w", path, err)
+	}
+
+	w.count(&w.numRemoved)
+
+	return nil
+}
+
+// installed returns true if the given existing content of the config file at
+// the given path, relative to the directory of the writer, was written by this
+// tool, as it was installed and wasn't modified since.
+func (w *configWriter) installed(relPath string, existing []byte) bool {
+	return w.manifest != nil && w.manifest.Tracked(relPath) && !w.manifest.Modified(relPath, existing)
+}
+
+// addDiff adds a pending diff of the file at the given path, from the given
+// existing content to the given content.
+func (w *configWriter) addDiff(path string, existingLabel string, label string, existing []byte, content []byte) {
+	unified := diff.Unified(existingLabel, label, string(existing), string(content), diff.DefaultContext)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.pendingDiffs[path] = unified
+}
+
+// writeFile writes the given content to the file at the given path, backing up
+// and recording the file in the install manifest, if the writer has one.
+func (w *configWriter) writeFile(relPath string, path string, existing []byte, exists bool, content []byte) error {
+	abort := func() error { return nil }
+
+	if w.manifest != nil {
+		var err error
+		if abort, err = w.manifest.Begin(relPath, existing, exists, content); err != nil {
+			return err
+		}
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0777)
+	if err == nil {
+		err = atomicfile.WriteFile(path, content, 0644)
+	}
+
+	if err != nil {
+		if abortErr := abort(); abortErr != nil {
+			return fmt.Errorf("writing %s: %v (and reverting the manifest: %v)", path, err, abortErr)
+		}
+
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// count increments the given counter of the writer.
+func (w *configWriter) count(counter *int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	*counter++
+}
+
+// Close completes the writes. In a dry run, the diffs are printed in order of
+// their paths, so that they're the same for every run.
+func (w *configWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	paths := make([]string, 0, len(w.pendingDiffs))
+	for path := range w.pendingDiffs {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if _, err := fmt.Fprint(w.diffs, w.pendingDiffs[path]); err != nil {
+			return err
+		}
+
+		delete(w.pendingDiffs, path)
+	}
+
+	return nil
+}
+
+// Summary returns a summary of the changes.
+func (w *configWriter) Summary() string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	verb := "written"
+	if w.dryRun {
+		verb = "would be written (dry run)"
+	}
+
+	summary := fmt.Sprintf(
+		"%d config files %s: %d created, %d modified, %d unchanged",
+		w.numCreated+w.numModified,
+		verb,
+		w.numCreated,
+		w.numModified,
+		w.numUnchanged,
+	)
+
+	if w.numRemoved > 0 {
+		summary += fmt.Sprintf(", %d removed (no longer generated)", w.numRemoved)
+	}
+
+	if w.numSkipped > 0 {
+		summary += fmt.Sprintf(", %d skipped (modified since installed)", w.numSkipped)
+	}
+
+	return summary + "\n"
+}
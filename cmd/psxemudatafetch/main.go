@@ -3,16 +3,34 @@
 package main
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"sort"
+	"time"
 
 	"github.com/Rican7/psx-emu-conf/internal/data"
+	"github.com/Rican7/psx-emu-conf/internal/data/source"
 	"github.com/Rican7/psx-emu-conf/internal/data/source/gdocechoj2"
 )
 
+const (
+	defaultPathToData = "_data/data.json"
+)
+
+var (
+	flagCheck      = flag.Bool("check", false, "check whether the fetched data differs from the existing data file, without writing any output")
+	flagPathToData = flag.String("data", defaultPathToData, "the path to the existing data file, used when checking")
+	flagPathOutput = flag.String("output", "", "a path to write the data file to, only if the data has changed (default: stdout)")
+	flagFormat     = flag.String("format", string(data.FormatJSON), "the format to write the data in: json, ndjson, or csv")
+
+	flagStrict         = flag.Bool("strict", false, "fail if any fetched app is invalid")
+	flagPathQuarantine = flag.String("quarantine", "", "a path to write any invalid apps to, to be inspected later")
+)
+
 // TODO:
 //
 //  - Abstract and organize a bit
@@ -21,33 +39,167 @@ import (
 //  - Merge the results into a combined result-set
 //  - Potentially report any differences between sources?
 func main() {
+	flag.Parse()
+
+	format, err := data.ParseFormat(*flagFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
 	ctx := context.Background()
 
 	src := gdocechoj2.New(os.Getenv("GOOGLE_API_KEY"))
 
 	apps, err := src.Fetch(ctx)
 	if err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 
 	for i := range apps {
 		apps[i].Normalize()
-
-		if err := apps[i].Validate(); err != nil {
-			fmt.Println(err)
-			continue
-		}
 	}
 
 	apps = mergeAppCollections(apps)
 
+	// Validate the merged apps, as merging can fill in or combine their data
+	for i := range apps {
+		apps[i].Normalize()
+	}
+
+	apps, err = filterValidApps(apps, *flagStrict, *flagPathQuarantine)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
 	sort.Sort(data.AppsDefault(apps))
 
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
+	generatedAt := time.Now().UTC().Truncate(time.Second)
+
+	dataFile := &data.File{
+		GeneratedAt: &generatedAt,
+		Sources:     describeSources(src),
+		Apps:        apps,
+	}
+
+	switch {
+	case *flagCheck:
+		changed, err := dataFileChanged(*flagPathToData, dataFile, format)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		if changed {
+			fmt.Fprintf(os.Stderr, "%s is out of date: re-fetching would change it\n", *flagPathToData)
+			os.Exit(1)
+		}
+	case *flagPathOutput != "":
+		changed, err := dataFileChanged(*flagPathOutput, dataFile, format)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		if !changed {
+			fmt.Fprintf(os.Stderr, "%s is up to date\n", *flagPathOutput)
+			return
+		}
+
+		if err := writeDataFile(*flagPathOutput, dataFile, format); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		if err := data.EncodeFormat(os.Stdout, dataFile, format); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
+
+// describeSources returns the information about each of the given sources.
+func describeSources(sources ...source.Source) []data.SourceInfo {
+	var infos []data.SourceInfo
+
+	for _, src := range sources {
+		if describer, ok := src.(source.Describer); ok {
+			infos = append(infos, describer.Describe())
+		}
+	}
+
+	return infos
+}
+
+// dataFileChanged returns true if writing the given data file to the given path,
+// in the given format, would change the existing file at that path.
+//
+// The generation time is ignored, as it changes on every fetch.
+func dataFileChanged(path string, dataFile *data.File, format data.Format) (bool, error) {
+	existing, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// Use the existing file's generation time, if it has one
+	comparable := *dataFile
+	if existingFile, err := data.Decode(bytes.NewReader(existing)); err == nil {
+		comparable.GeneratedAt = existingFile.GeneratedAt
+	}
+
+	var output bytes.Buffer
+	if err := data.EncodeFormat(&output, &comparable, format); err != nil {
+		return false, err
+	}
+
+	return !bytes.Equal(existing, output.Bytes()), nil
+}
+
+func writeDataFile(path string, dataFile *data.File, format data.Format) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	if err := data.EncodeFormat(file, dataFile, format); err != nil {
+		return err
+	}
 
-	encoder.Encode(apps)
+	return file.Close()
+}
+
+// filterValidApps validates the given apps and returns only the valid ones,
+// reporting the validation problems and a summary to stderr.
+//
+// If a quarantine path is given, any invalid apps are written to a data file at
+// that path. If strict is true, an error is returned if any apps are invalid.
+func filterValidApps(apps []data.App, strict bool, quarantinePath string) ([]data.App, error) {
+	report := data.ValidateApps(apps)
+
+	for _, err := range report.Errors {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	fmt.Fprint(os.Stderr, report.Summary())
+
+	if quarantinePath != "" && len(report.Invalid) > 0 {
+		if err := writeDataFile(quarantinePath, &data.File{Apps: report.Invalid}, data.FormatJSON); err != nil {
+			return nil, err
+		}
+	}
+
+	if strict && len(report.Invalid) > 0 {
+		return nil, fmt.Errorf("%d invalid apps found in strict mode", len(report.Invalid))
+	}
+
+	return report.Valid, nil
 }
 
 // mergeAppCollections merges multiple collections of apps into one large
@@ -59,6 +211,7 @@ func mergeAppCollections(appCollections ...[]data.App) []data.App {
 	var mergedApps []data.App
 
 	appsBySerialCode := make(map[string]data.App)
+	var serialCodes []string // Keeps track of the order of the serial codes
 	var appsWithoutIndex []data.App
 
 	for _, appCollection := range appCollections {
@@ -68,6 +221,8 @@ func mergeAppCollections(appCollections ...[]data.App) []data.App {
 
 				if ok {
 					app = mergeApps(appBySerialCode, app)
+				} else {
+					serialCodes = append(serialCodes, app.SerialCode)
 				}
 
 				appsBySerialCode[app.SerialCode] = app
@@ -77,8 +232,10 @@ func mergeAppCollections(appCollections ...[]data.App) []data.App {
 		}
 	}
 
-	for _, app := range appsBySerialCode {
-		mergedApps = append(mergedApps, app)
+	// Iterate in the order that the serial codes were first found, rather than
+	// over the map, so that the result is deterministic.
+	for _, serialCode := range serialCodes {
+		mergedApps = append(mergedApps, appsBySerialCode[serialCode])
 	}
 	for _, app := range appsWithoutIndex {
 		mergedApps = append(mergedApps, app)
@@ -130,6 +287,15 @@ func mergeApps(appPrimary data.App, appSecondary data.App) data.App {
 		app.FeatureSupport.RumbleSupport = appSecondary.FeatureSupport.RumbleSupport
 	}
 
+	app.FeatureSupport.MultitapSupport = mergePeripheralSupport(app.FeatureSupport.MultitapSupport, appSecondary.FeatureSupport.MultitapSupport)
+	app.FeatureSupport.MouseSupport = mergePeripheralSupport(app.FeatureSupport.MouseSupport, appSecondary.FeatureSupport.MouseSupport)
+	app.FeatureSupport.GunConSupport = mergePeripheralSupport(app.FeatureSupport.GunConSupport, appSecondary.FeatureSupport.GunConSupport)
+	app.FeatureSupport.JustifierSupport = mergePeripheralSupport(app.FeatureSupport.JustifierSupport, appSecondary.FeatureSupport.JustifierSupport)
+	app.FeatureSupport.NeGconSupport = mergePeripheralSupport(app.FeatureSupport.NeGconSupport, appSecondary.FeatureSupport.NeGconSupport)
+	app.FeatureSupport.JogConSupport = mergePeripheralSupport(app.FeatureSupport.JogConSupport, appSecondary.FeatureSupport.JogConSupport)
+	app.FeatureSupport.LinkCableSupport = mergePeripheralSupport(app.FeatureSupport.LinkCableSupport, appSecondary.FeatureSupport.LinkCableSupport)
+	app.FeatureSupport.PocketStationSupport = mergePeripheralSupport(app.FeatureSupport.PocketStationSupport, appSecondary.FeatureSupport.PocketStationSupport)
+
 	app.TitleVariations = append(app.TitleVariations, appSecondary.TitleVariations...)
 	app.DiscNames = append(app.DiscNames, appSecondary.DiscNames...)
 
@@ -138,3 +304,13 @@ func mergeApps(appPrimary data.App, appSecondary data.App) data.App {
 
 	return app
 }
+
+// mergePeripheralSupport merges two peripheral support levels, preferring the
+// primary level unless it's unknown.
+func mergePeripheralSupport(primary, secondary data.PeripheralSupport) data.PeripheralSupport {
+	if primary == data.PeripheralSupportUnknown {
+		return secondary
+	}
+
+	return primary
+}
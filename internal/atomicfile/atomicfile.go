@@ -0,0 +1,57 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+// Package atomicfile provides atomic writes of files, so that a file is either
+// completely written or left untouched, and never partially written.
+package atomicfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes the given content to the file at the given path, like
+// ioutil.WriteFile, but atomically.
+//
+// The content is written to a temporary file in the same directory, which is
+// then renamed into place only once it's completely written. If the file
+// already exists, its permissions are kept, and otherwise the given
+// permissions are used as-is, regardless of the umask.
+//
+// NOTE: The temporary file isn't synced to disk before the rename, so that
+// many files can be written quickly. This protects against failed and
+// interrupted writes, but not against a crash of the system.
+func WriteFile(path string, content []byte, perm os.FileMode) (err error) {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	// Prefix with a dot and suffix with an unrelated extension, so that the
+	// temporary file isn't mistaken for the real one
+	file, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	if _, err = file.Write(content); err != nil {
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	// Temporary files are created private to the user
+	if err = os.Chmod(file.Name(), perm); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,304 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+// Package dat provides mechanisms to read and write clrmamepro DAT files, such
+// as the "metadat" files of the libretro-database.
+//
+// A clrmamepro DAT file is a list of blocks, each containing key/value pairs or
+// nested blocks, like:
+//
+//	clrmamepro (
+//		name "Sony - PlayStation"
+//	)
+//
+//	game (
+//		name "Ape Escape (USA)"
+//		serial "SCUS-94423"
+//		analog "true"
+//	)
+//
+// See:
+//  - https://github.com/libretro/libretro-database
+//  - https://github.com/libretro/libretro-database/tree/master/metadat
+package dat
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Common keys and block types.
+const (
+	BlockTypeHeader = "clrmamepro"
+	BlockTypeGame   = "game"
+
+	KeyName        = "name"
+	KeyDescription = "description"
+	KeySerial      = "serial"
+)
+
+// Entry defines an entry of a DAT file, which is either a key/value pair or a
+// block of nested entries.
+type Entry struct {
+	Key      string
+	Value    string  // The value of a key/value pair entry.
+	Children []Entry // The nested entries of a block entry.
+
+	// The value token as it was parsed, so that an untouched value is written
+	// back as it was read, quoted or not.
+	rawValue string
+}
+
+// IsBlock returns true if the entry is a block of nested entries.
+func (e Entry) IsBlock() bool {
+	return e.Children != nil
+}
+
+// Get returns the value of the first nested key/value pair entry with the given
+// key, or an empty string if there isn't one.
+func (e Entry) Get(key string) string {
+	for _, child := range e.Children {
+		if child.Key == key && !child.IsBlock() {
+			return child.Value
+		}
+	}
+
+	return ""
+}
+
+// File defines the structure of a DAT file, as a list of top-level blocks.
+type File struct {
+	Entries []Entry
+}
+
+// Games returns the game blocks of the file.
+func (f *File) Games() []Entry {
+	var games []Entry
+
+	for _, entry := range f.Entries {
+		if entry.Key == BlockTypeGame && entry.IsBlock() {
+			games = append(games, entry)
+		}
+	}
+
+	return games
+}
+
+// Parse reads a DAT file from the given reader.
+func Parse(reader io.Reader) (*File, error) {
+	t := &tokenizer{reader: bufio.NewReader(reader)}
+
+	entries, err := parseEntries(t, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{Entries: entries}, nil
+}
+
+// Write writes the given DAT file to the given writer.
+//
+// Values that were parsed are written as they were read, unless they changed.
+// An error is returned if a value can't be represented in a DAT file.
+func Write(writer io.Writer, file *File) error {
+	w := bufio.NewWriter(writer)
+
+	for i, entry := range file.Entries {
+		if i > 0 {
+			w.WriteString("\n")
+		}
+
+		if err := writeEntry(w, entry, 0); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+func writeEntry(w *bufio.Writer, entry Entry, depth int) error {
+	indent := strings.Repeat("\t", depth)
+
+	if !entry.IsBlock() {
+		value, err := formatValue(entry)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "%s%s %s\n", indent, entry.Key, value)
+		return nil
+	}
+
+	fmt.Fprintf(w, "%s%s (\n", indent, entry.Key)
+
+	for _, child := range entry.Children {
+		if err := writeEntry(w, child, depth+1); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "%s)\n", indent)
+
+	return nil
+}
+
+// formatValue returns the value of a key/value pair entry in the form it's
+// written in: as it was parsed if it's unchanged, or quoted otherwise.
+//
+// NOTE: The clrmamepro format has no escape sequences, so a value containing
+// double quotes can't be represented.
+func formatValue(entry Entry) (string, error) {
+	if entry.rawValue != "" && unquote(entry.rawValue) == entry.Value {
+		return entry.rawValue, nil
+	}
+
+	if strings.Contains(entry.Value, `"`) {
+		return "", fmt.Errorf("value %q of %q can't be written to a DAT file, as it contains a double quote", entry.Value, entry.Key)
+	}
+
+	return `"` + entry.Value + `"`, nil
+}
+
+// unquote returns the value of the given value token.
+func unquote(rawValue string) string {
+	if len(rawValue) >= 2 && strings.HasPrefix(rawValue, `"`) {
+		return rawValue[1 : len(rawValue)-1]
+	}
+
+	return rawValue
+}
+
+// parseEntries parses entries until the end of the input, or until the closing
+// parenthesis of a block if within one.
+func parseEntries(t *tokenizer, inBlock bool) ([]Entry, error) {
+	entries := []Entry{}
+
+	for {
+		token, err := t.next()
+		if err == io.EOF {
+			if inBlock {
+				return nil, errors.New("unexpected end of DAT file within a block")
+			}
+
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case token.kind == tokenClose && inBlock:
+			return entries, nil
+		case token.kind != tokenWord:
+			return nil, fmt.Errorf("unexpected %q on line %d of DAT file", token.text, token.line)
+		}
+
+		key := token.text
+
+		valueToken, err := t.next()
+		if err != nil {
+			return nil, fmt.Errorf("missing value of %q on line %d of DAT file", key, token.line)
+		}
+
+		switch valueToken.kind {
+		case tokenOpen:
+			children, err := parseEntries(t, true)
+			if err != nil {
+				return nil, err
+			}
+
+			entries = append(entries, Entry{Key: key, Children: children})
+		case tokenWord, tokenString:
+			entries = append(entries, Entry{Key: key, Value: valueToken.text, rawValue: valueToken.raw})
+		default:
+			return nil, fmt.Errorf("unexpected %q on line %d of DAT file", valueToken.text, valueToken.line)
+		}
+	}
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	raw  string // The text as it was read, including any quotes.
+	line int
+}
+
+type tokenizer struct {
+	reader *bufio.Reader
+	line   int
+}
+
+func (t *tokenizer) next() (token, error) {
+	if t.line == 0 {
+		t.line = 1
+	}
+
+	// Skip whitespace
+	var r rune
+	for {
+		var err error
+		if r, _, err = t.reader.ReadRune(); err != nil {
+			return token{}, err
+		}
+
+		if r == '\n' {
+			t.line++
+		}
+
+		if !unicode.IsSpace(r) {
+			break
+		}
+	}
+
+	switch r {
+	case '(':
+		return token{kind: tokenOpen, text: "(", line: t.line}, nil
+	case ')':
+		return token{kind: tokenClose, text: ")", line: t.line}, nil
+	case '"':
+		line := t.line
+
+		text, err := t.reader.ReadString('"')
+		if err != nil {
+			return token{}, fmt.Errorf("unterminated string on line %d of DAT file", line)
+		}
+
+		t.line += strings.Count(text, "\n")
+
+		return token{kind: tokenString, text: strings.TrimSuffix(text, `"`), raw: `"` + text, line: line}, nil
+	}
+
+	var builder strings.Builder
+	builder.WriteRune(r)
+
+	for {
+		r, _, err := t.reader.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return token{}, err
+		}
+
+		if unicode.IsSpace(r) || r == '(' || r == ')' {
+			t.reader.UnreadRune()
+			break
+		}
+
+		builder.WriteRune(r)
+	}
+
+	return token{kind: tokenWord, text: builder.String(), raw: builder.String(), line: t.line}, nil
+}
@@ -0,0 +1,238 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package dat
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseWrite(t *testing.T) {
+	tests := []struct {
+		name string
+		dat  string
+		want []Entry
+
+		// The written form, if it isn't the same as the parsed DAT.
+		wantWritten string
+	}{
+		{
+			name: "empty",
+			dat:  "",
+			want: []Entry{},
+		},
+		{
+			name: "header and game",
+			dat: "clrmamepro (\n" +
+				"\tname \"Sony - PlayStation\"\n" +
+				")\n" +
+				"\n" +
+				"game (\n" +
+				"\tname \"Ape Escape (USA)\"\n" +
+				"\tserial \"SCUS-94423\"\n" +
+				"\tanalog \"true\"\n" +
+				")\n",
+			want: []Entry{
+				{Key: BlockTypeHeader, Children: []Entry{
+					{Key: KeyName, Value: "Sony - PlayStation"},
+				}},
+				{Key: BlockTypeGame, Children: []Entry{
+					{Key: KeyName, Value: "Ape Escape (USA)"},
+					{Key: KeySerial, Value: "SCUS-94423"},
+					{Key: "analog", Value: "true"},
+				}},
+			},
+		},
+		{
+			name: "nested and empty blocks",
+			dat: "game (\n" +
+				"\tname \"Tobal 2 (Japan)\"\n" +
+				"\trom (\n" +
+				"\t\tname \"Tobal 2 (Japan).cue\"\n" +
+				"\t\tsize \"1234\"\n" +
+				"\t)\n" +
+				"\textra (\n" +
+				"\t)\n" +
+				")\n",
+			want: []Entry{
+				{Key: BlockTypeGame, Children: []Entry{
+					{Key: KeyName, Value: "Tobal 2 (Japan)"},
+					{Key: "rom", Children: []Entry{
+						{Key: KeyName, Value: "Tobal 2 (Japan).cue"},
+						{Key: "size", Value: "1234"},
+					}},
+					{Key: "extra", Children: []Entry{}},
+				}},
+			},
+		},
+		{
+			name: "unquoted values and compact layout",
+			dat:  "game ( name Foo serial \"SLUS-00001\" rom ( size 12 ) )",
+			want: []Entry{
+				{Key: BlockTypeGame, Children: []Entry{
+					{Key: KeyName, Value: "Foo"},
+					{Key: KeySerial, Value: "SLUS-00001"},
+					{Key: "rom", Children: []Entry{
+						{Key: "size", Value: "12"},
+					}},
+				}},
+			},
+			wantWritten: "game (\n" +
+				"\tname Foo\n" +
+				"\tserial \"SLUS-00001\"\n" +
+				"\trom (\n" +
+				"\t\tsize 12\n" +
+				"\t)\n" +
+				")\n",
+		},
+		{
+			name: "parentheses and spaces within strings",
+			dat:  "game (\n\tname \"Foo (USA) (Disc 1)\"\n)\n",
+			want: []Entry{
+				{Key: BlockTypeGame, Children: []Entry{
+					{Key: KeyName, Value: "Foo (USA) (Disc 1)"},
+				}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file, err := Parse(strings.NewReader(test.dat))
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+
+			if !reflect.DeepEqual(withoutRawValues(file.Entries), test.want) {
+				t.Errorf("Parse = %#v, want %#v", file.Entries, test.want)
+			}
+
+			var written bytes.Buffer
+			if err := Write(&written, file); err != nil {
+				t.Fatalf("Write returned error: %v", err)
+			}
+
+			wantWritten := test.wantWritten
+			if wantWritten == "" {
+				wantWritten = test.dat
+			}
+
+			if written.String() != wantWritten {
+				t.Errorf("Write wrote %q, want %q", written.String(), wantWritten)
+			}
+
+			// The written DAT must parse back into the same entries
+			reparsed, err := Parse(&written)
+			if err != nil {
+				t.Fatalf("Parse of the written DAT returned error: %v", err)
+			}
+
+			if !reflect.DeepEqual(reparsed.Entries, file.Entries) {
+				t.Errorf("Parse of the written DAT = %#v, want %#v", reparsed.Entries, file.Entries)
+			}
+		})
+	}
+}
+
+func TestWriteChangedValues(t *testing.T) {
+	file, err := Parse(strings.NewReader("game ( name Foo rom ( size 12 ) )"))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	// Changed values are quoted, while untouched ones are kept as they were
+	file.Entries[0].Children[1].Children[0].Value = "13"
+
+	var written bytes.Buffer
+	if err := Write(&written, file); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	want := "game (\n\tname Foo\n\trom (\n\t\tsize \"13\"\n\t)\n)\n"
+	if written.String() != want {
+		t.Errorf("Write wrote %q, want %q", written.String(), want)
+	}
+}
+
+func TestWriteUnrepresentable(t *testing.T) {
+	file := &File{Entries: []Entry{
+		{Key: BlockTypeGame, Children: []Entry{
+			{Key: KeyName, Value: `The "Best" Game`},
+		}},
+	}}
+
+	if err := Write(ioutil.Discard, file); err == nil {
+		t.Errorf("Write of %q returned no error", file.Entries[0].Children[0].Value)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		dat  string
+	}{
+		{"unclosed block", "game (\n\tname \"Foo\"\n"},
+		{"unterminated string", "game (\n\tname \"Foo\n)\n"},
+		{"missing value", "game (\n\tname"},
+		{"stray close", ")\n"},
+		{"block without key", "( name \"Foo\" )\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(test.dat)); err == nil {
+				t.Errorf("Parse(%q) returned no error", test.dat)
+			}
+		})
+	}
+}
+
+func TestGames(t *testing.T) {
+	file, err := Parse(strings.NewReader(
+		"clrmamepro ( name \"Header\" )\n" +
+			"game ( name \"A\" serial \"SLUS-00001\" )\n" +
+			"game \"not a block\"\n" +
+			"game ( name \"B\" )\n",
+	))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	games := file.Games()
+
+	var names []string
+	for _, game := range games {
+		names = append(names, game.Get(KeyName))
+	}
+
+	if want := []string{"A", "B"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Games names = %q, want %q", names, want)
+	}
+
+	if got := games[0].Get(KeySerial); got != "SLUS-00001" {
+		t.Errorf("Get(%q) = %q, want %q", KeySerial, got, "SLUS-00001")
+	}
+
+	if got := games[1].Get(KeySerial); got != "" {
+		t.Errorf("Get(%q) = %q, want empty", KeySerial, got)
+	}
+}
+
+// withoutRawValues returns a copy of the given entries without their raw
+// values, to compare them with entries that weren't parsed.
+func withoutRawValues(entries []Entry) []Entry {
+	if entries == nil {
+		return nil
+	}
+
+	copied := make([]Entry, len(entries))
+
+	for i, entry := range entries {
+		copied[i] = Entry{Key: entry.Key, Value: entry.Value, Children: withoutRawValues(entry.Children)}
+	}
+
+	return copied
+}
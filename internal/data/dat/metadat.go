@@ -0,0 +1,208 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package dat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Rican7/psx-emu-conf/internal/data"
+)
+
+// SystemName defines the name of the system of the libretro-database DAT files.
+const SystemName = "Sony - PlayStation"
+
+// valueTrue defines the value of a feature key, when the feature is supported.
+const valueTrue = "true"
+
+// Feature defines a feature that has a libretro-database "metadat" DAT file.
+type Feature string
+
+// The features that have a libretro-database "metadat" DAT file.
+const (
+	FeatureAnalog Feature = "analog"
+	FeatureRumble Feature = "rumble"
+)
+
+// Features returns the features that have a libretro-database "metadat" DAT
+// file.
+func Features() []Feature {
+	return []Feature{FeatureAnalog, FeatureRumble}
+}
+
+// ParseFeature returns the feature of the given name.
+func ParseFeature(name string) (Feature, error) {
+	for _, feature := range Features() {
+		if string(feature) == strings.ToLower(strings.TrimSpace(name)) {
+			return feature, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown DAT feature %q", name)
+}
+
+// supports returns whether the given app is known to support the feature, and
+// whether the support of the feature is known at all.
+func (f Feature) supports(app data.App) (supported bool, known bool) {
+	switch f {
+	case FeatureAnalog:
+		switch app.FeatureSupport.AnalogSupport {
+		case data.AnalogSupportYes, data.AnalogSupportRequired:
+			return true, true
+		case data.AnalogSupportNo:
+			return false, true
+		}
+	case FeatureRumble:
+		switch app.FeatureSupport.RumbleSupport {
+		case data.RumbleSupportYes:
+			return true, true
+		case data.RumbleSupportNo:
+			return false, true
+		}
+	}
+
+	return false, false
+}
+
+// Export returns a "metadat" DAT file of the given feature, containing a game
+// for each of the given apps that are known to support the feature.
+//
+// The games are sorted by name, then serial code, like the upstream files.
+// Apps without a serial code are skipped, as the DAT files are matched by
+// serial code.
+func Export(apps []data.App, feature Feature) *File {
+	var games []Entry
+
+	for _, app := range apps {
+		if supported, _ := feature.supports(app); supported && app.SerialCode != "" {
+			games = append(games, newGame(app, feature))
+		}
+	}
+
+	sort.SliceStable(games, func(i, j int) bool {
+		if nameI, nameJ := games[i].Get(KeyName), games[j].Get(KeyName); nameI != nameJ {
+			return nameI < nameJ
+		}
+
+		return games[i].Get(KeySerial) < games[j].Get(KeySerial)
+	})
+
+	return &File{
+		Entries: append([]Entry{newHeader()}, games...),
+	}
+}
+
+// ChangeKind defines the kind of a change to a DAT file.
+type ChangeKind string
+
+// The kinds of changes to a DAT file.
+const (
+	ChangeAdded   ChangeKind = "+"
+	ChangeRemoved ChangeKind = "-"
+)
+
+// Change defines a change of a game in a DAT file.
+type Change struct {
+	Kind   ChangeKind
+	Name   string
+	Serial string
+}
+
+// String returns a human readable description of the change.
+func (c Change) String() string {
+	return fmt.Sprintf("%s %s (%s)", c.Kind, c.Serial, c.Name)
+}
+
+// Diff returns a copy of the given existing "metadat" DAT file of the given
+// feature, with the changes from the given apps applied, and the list of those
+// changes.
+//
+// Only real changes are made, so that the result can be contributed upstream:
+//  - Games are only added if an app is known to support the feature
+//  - Games are only removed if an app is known to NOT support the feature
+//  - Games of apps with an unknown support level, or without an app, are kept
+//  - The header, the order, and the fields of the existing games are retained
+func Diff(existing *File, apps []data.App, feature Feature) (*File, []Change) {
+	appsBySerial := make(map[string]data.App, len(apps))
+	for _, app := range apps {
+		if app.SerialCode != "" {
+			appsBySerial[app.SerialCode] = app
+		}
+	}
+
+	result := &File{}
+	existingSerials := make(map[string]bool)
+	var changes []Change
+
+	for _, entry := range existing.Entries {
+		if entry.Key != BlockTypeGame || !entry.IsBlock() {
+			result.Entries = append(result.Entries, entry)
+			continue
+		}
+
+		serial := entry.Get(KeySerial)
+		existingSerials[serial] = true
+
+		if app, ok := appsBySerial[serial]; ok {
+			if supported, known := feature.supports(app); known && !supported {
+				changes = append(changes, Change{Kind: ChangeRemoved, Name: entry.Get(KeyName), Serial: serial})
+				continue
+			}
+		}
+
+		result.Entries = append(result.Entries, entry)
+	}
+
+	for _, app := range apps {
+		if supported, _ := feature.supports(app); !supported || app.SerialCode == "" || existingSerials[app.SerialCode] {
+			continue
+		}
+
+		existingSerials[app.SerialCode] = true
+
+		result.Entries = insertGame(result.Entries, newGame(app, feature))
+		changes = append(changes, Change{Kind: ChangeAdded, Name: app.Title, Serial: app.SerialCode})
+	}
+
+	return result, changes
+}
+
+// insertGame inserts the given game before the first game with a greater
+// name, to keep name-sorted files sorted, or at the end otherwise.
+func insertGame(entries []Entry, game Entry) []Entry {
+	name := game.Get(KeyName)
+
+	for i, entry := range entries {
+		if entry.Key == BlockTypeGame && entry.IsBlock() && entry.Get(KeyName) > name {
+			entries = append(entries, Entry{})
+			copy(entries[i+1:], entries[i:])
+			entries[i] = game
+
+			return entries
+		}
+	}
+
+	return append(entries, game)
+}
+
+func newHeader() Entry {
+	return Entry{
+		Key: BlockTypeHeader,
+		Children: []Entry{
+			{Key: KeyName, Value: SystemName},
+			{Key: KeyDescription, Value: SystemName},
+		},
+	}
+}
+
+func newGame(app data.App, feature Feature) Entry {
+	return Entry{
+		Key: BlockTypeGame,
+		Children: []Entry{
+			{Key: KeyName, Value: app.Title},
+			{Key: KeySerial, Value: app.SerialCode},
+			{Key: string(feature), Value: valueTrue},
+		},
+	}
+}
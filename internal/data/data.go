@@ -3,6 +3,8 @@
 // Package data defines structures and mechanisms for PlayStation data.
 package data
 
+import "github.com/Rican7/psx-emu-conf/internal/data/serial"
+
 // App defines the structure of a PlayStation software title.
 //
 // These are called "App" rather than "Game", to support non-game releases
@@ -29,11 +31,43 @@ const (
 	RegionPAL   Region = "PAL"
 )
 
+// A map of serial code regions to the Region that they're released in.
+var serialRegions = map[serial.Region]Region{
+	serial.RegionAmerica: RegionNTSCU,
+	serial.RegionAsia:    RegionNTSCJ,
+	serial.RegionEurope:  RegionPAL,
+	serial.RegionJapan:   RegionNTSCJ,
+	serial.RegionKorea:   RegionNTSCJ,
+}
+
+// RegionForSerialCode returns the Region denoted by the prefix of the given
+// serial code, or an empty Region if it can't be determined.
+func RegionForSerialCode(serialCode string) Region {
+	code, err := serial.Parse(serialCode)
+	if err != nil {
+		return ""
+	}
+
+	return serialRegions[code.Region()]
+}
+
 // FeatureSupport defines a structure that represents the PlayStation features
 // and peripherals support matrix.
+//
+// NOTE: The peripheral support levels are omitted from JSON when unknown, as
+// they're only known for a fraction of titles.
 type FeatureSupport struct {
 	AnalogSupport AnalogSupport
 	RumbleSupport RumbleSupport
+
+	MultitapSupport      PeripheralSupport `json:",omitempty"`
+	MouseSupport         PeripheralSupport `json:",omitempty"`
+	GunConSupport        PeripheralSupport `json:",omitempty"`
+	JustifierSupport     PeripheralSupport `json:",omitempty"`
+	NeGconSupport        PeripheralSupport `json:",omitempty"`
+	JogConSupport        PeripheralSupport `json:",omitempty"`
+	LinkCableSupport     PeripheralSupport `json:",omitempty"`
+	PocketStationSupport PeripheralSupport `json:",omitempty"`
 }
 
 // AnalogSupport defines the level of support of an "Analog" controller.
@@ -56,3 +90,45 @@ const (
 	RumbleSupportNo                           // No support.
 	RumbleSupportYes                          // Supports rumble.
 )
+
+// PeripheralSupport defines the level of support of a PlayStation peripheral,
+// such as the Multitap, the Mouse, or the GunCon.
+type PeripheralSupport uint
+
+// Peripheral support levels.
+const (
+	PeripheralSupportUnknown  PeripheralSupport = iota // Unknown level of support.
+	PeripheralSupportNo                                // No support.
+	PeripheralSupportYes                               // Supports the peripheral.
+	PeripheralSupportRequired                          // The peripheral is required.
+)
+
+// peripheral defines a peripheral support field of a FeatureSupport.
+type peripheral struct {
+	name  string             // The name of the field. Ex: "GunConSupport"
+	level *PeripheralSupport // A pointer to the support level of the field.
+
+	// Whether the peripheral is an input device, used in place of a standard
+	// controller, rather than alongside one.
+	input bool
+}
+
+// peripherals returns the peripheral support fields of the FeatureSupport, in
+// the order that they're declared.
+func (f *FeatureSupport) peripherals() []peripheral {
+	return []peripheral{
+		{"MultitapSupport", &f.MultitapSupport, false},
+		{"MouseSupport", &f.MouseSupport, true},
+		{"GunConSupport", &f.GunConSupport, true},
+		{"JustifierSupport", &f.JustifierSupport, true},
+		{"NeGconSupport", &f.NeGconSupport, true},
+		{"JogConSupport", &f.JogConSupport, true},
+		{"LinkCableSupport", &f.LinkCableSupport, false},
+		{"PocketStationSupport", &f.PocketStationSupport, false},
+	}
+}
+
+// Supports returns true if the peripheral is known to be supported.
+func (s PeripheralSupport) Supports() bool {
+	return s == PeripheralSupportYes || s == PeripheralSupportRequired
+}
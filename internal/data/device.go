@@ -0,0 +1,179 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package data
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InputDevice defines a PlayStation input device, that's plugged into a
+// controller port.
+type InputDevice string
+
+// Input devices.
+const (
+	InputDeviceStandard  InputDevice = "standard"  // The original digital controller.
+	InputDeviceAnalog    InputDevice = "analog"    // An analog controller, without rumble.
+	InputDeviceDualShock InputDevice = "dualshock" // An analog controller, with rumble.
+	InputDeviceMouse     InputDevice = "mouse"
+	InputDeviceNeGcon    InputDevice = "negcon"
+	InputDeviceJogCon    InputDevice = "jogcon"
+	InputDeviceGunCon    InputDevice = "guncon"
+	InputDeviceJustifier InputDevice = "justifier"
+)
+
+// InputDevices returns all of the input devices.
+func InputDevices() []InputDevice {
+	return []InputDevice{
+		InputDeviceStandard,
+		InputDeviceAnalog,
+		InputDeviceDualShock,
+		InputDeviceMouse,
+		InputDeviceNeGcon,
+		InputDeviceJogCon,
+		InputDeviceGunCon,
+		InputDeviceJustifier,
+	}
+}
+
+// ParseInputDevice returns the input device of the given name.
+func ParseInputDevice(name string) (InputDevice, error) {
+	for _, device := range InputDevices() {
+		if string(device) == strings.ToLower(strings.TrimSpace(name)) {
+			return device, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown input device %q", name)
+}
+
+// IsController returns true if the input device is a standard controller, or
+// one of its analog successors.
+func (d InputDevice) IsController() bool {
+	switch d {
+	case InputDeviceStandard, InputDeviceAnalog, InputDeviceDualShock:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsLightGun returns true if the input device is a light gun.
+func (d InputDevice) IsLightGun() bool {
+	return d == InputDeviceGunCon || d == InputDeviceJustifier
+}
+
+// peripheralSupport returns the support level of the input device, if it's a
+// peripheral, and whether it's a peripheral at all.
+func (f FeatureSupport) peripheralSupport(device InputDevice) (PeripheralSupport, bool) {
+	switch device {
+	case InputDeviceMouse:
+		return f.MouseSupport, true
+	case InputDeviceNeGcon:
+		return f.NeGconSupport, true
+	case InputDeviceJogCon:
+		return f.JogConSupport, true
+	case InputDeviceGunCon:
+		return f.GunConSupport, true
+	case InputDeviceJustifier:
+		return f.JustifierSupport, true
+	default:
+		return PeripheralSupportUnknown, false
+	}
+}
+
+// Supports returns true if the input device is known to be usable, or for the
+// standard controller, not known to be unusable.
+func (f FeatureSupport) Supports(device InputDevice) bool {
+	if support, ok := f.peripheralSupport(device); ok {
+		return support.Supports()
+	}
+
+	switch device {
+	case InputDeviceAnalog:
+		return f.AnalogSupport == AnalogSupportYes || f.AnalogSupport == AnalogSupportRequired
+	case InputDeviceDualShock:
+		return f.RumbleSupport == RumbleSupportYes ||
+			f.AnalogSupport == AnalogSupportYes || f.AnalogSupport == AnalogSupportRequired
+	case InputDeviceStandard:
+		if f.AnalogSupport == AnalogSupportRequired {
+			return false
+		}
+
+		for _, device := range InputDevices() {
+			if support, ok := f.peripheralSupport(device); ok && support == PeripheralSupportRequired {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// InputDevices returns the input devices that are supported, from the most to
+// the least appropriate, given the input devices that the user prefers, from
+// the most to the least preferred.
+//
+// The input devices are ordered by:
+//  - An input peripheral that's required
+//  - The preferred input peripherals
+//  - A light gun, as light gun games are built for them
+//  - The preferred controllers
+//  - The DualShock controller, for rumble
+//  - The most capable controller: analog, then DualShock, then standard
+//  - The other input peripherals
+//
+// Preferred controllers only take precedence over the other controllers, and
+// never over the peripherals that an app supports.
+func (f FeatureSupport) InputDevices(preferred ...InputDevice) []InputDevice {
+	var devices []InputDevice
+	added := make(map[InputDevice]bool)
+
+	add := func(device InputDevice) {
+		if !added[device] && f.Supports(device) {
+			added[device] = true
+			devices = append(devices, device)
+		}
+	}
+
+	for _, device := range InputDevices() {
+		if support, ok := f.peripheralSupport(device); ok && support == PeripheralSupportRequired {
+			add(device)
+		}
+	}
+
+	var preferredControllers []InputDevice
+
+	for _, device := range preferred {
+		if device.IsController() {
+			preferredControllers = append(preferredControllers, device)
+			continue
+		}
+
+		add(device)
+	}
+
+	add(InputDeviceGunCon)
+	add(InputDeviceJustifier)
+
+	for _, device := range preferredControllers {
+		add(device)
+	}
+
+	if f.RumbleSupport == RumbleSupportYes {
+		add(InputDeviceDualShock)
+	}
+
+	add(InputDeviceAnalog)
+	add(InputDeviceDualShock)
+	add(InputDeviceStandard)
+
+	for _, device := range InputDevices() {
+		add(device)
+	}
+
+	return devices
+}
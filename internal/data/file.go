@@ -0,0 +1,152 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// CurrentSchemaVersion defines the version of the data file format that's
+// written by this package.
+//
+// Version history:
+//  - 1: A bare JSON array of apps
+//  - 2: A JSON object "envelope", with metadata and the apps
+const CurrentSchemaVersion = 2
+
+// ErrUnsupportedVersion is returned (wrapped) when a data file's schema version
+// is newer than what's supported.
+var ErrUnsupportedVersion = errors.New("unsupported data file schema version")
+
+// File defines the structure of a data file.
+//
+// NOTE: The Apps are purposefully the last field, so that the metadata is
+// encoded before the (large) list of apps.
+type File struct {
+	SchemaVersion uint
+	GeneratedAt   *time.Time   `json:",omitempty"`
+	Sources       []SourceInfo `json:",omitempty"`
+
+	Apps []App
+}
+
+// SourceInfo defines the structure of the information about a data source that
+// the data was fetched from.
+type SourceInfo struct {
+	Name     string
+	URL      string `json:",omitempty"`
+	Revision string `json:",omitempty"`
+}
+
+// migration defines a function that migrates a raw data file from one schema
+// version to the next.
+type migration func(raw []byte) ([]byte, error)
+
+// The migrations, keyed by the schema version that they migrate from.
+var migrations = map[uint]migration{
+	1: migrateV1ToV2,
+}
+
+// Decode reads a data file from the given reader, migrating it from an older
+// schema version if necessary.
+func Decode(reader io.Reader) (*File, error) {
+	raw, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := detectSchemaVersion(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if version > CurrentSchemaVersion {
+		return nil, fmt.Errorf("%w: %d (max %d)", ErrUnsupportedVersion, version, CurrentSchemaVersion)
+	}
+
+	for ; version < CurrentSchemaVersion; version++ {
+		migrate, ok := migrations[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: no migration from version %d", ErrUnsupportedVersion, version)
+		}
+
+		if raw, err = migrate(raw); err != nil {
+			return nil, fmt.Errorf("migrating from version %d: %w", version, err)
+		}
+	}
+
+	var file File
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+// Encode writes the given data file to the given writer, in the current schema
+// version.
+func Encode(writer io.Writer, file *File) error {
+	return EncodeFormat(writer, file, FormatJSON)
+}
+
+// EncodeFormat writes the given data file to the given writer, in the given
+// format. The metadata of the data file is only written if the format supports
+// metadata.
+func EncodeFormat(writer io.Writer, file *File, format Format) error {
+	appWriter := NewAppWriter(writer, format, *file)
+
+	for _, app := range file.Apps {
+		if err := appWriter.Write(app); err != nil {
+			return err
+		}
+	}
+
+	return appWriter.Close()
+}
+
+// detectSchemaVersion detects the schema version of a raw data file.
+func detectSchemaVersion(raw []byte) (uint, error) {
+	trimmed := bytes.TrimSpace(raw)
+
+	if len(trimmed) == 0 {
+		return 0, errors.New("empty data file")
+	}
+
+	switch trimmed[0] {
+	case '[':
+		return 1, nil
+	case '{':
+		var header struct {
+			SchemaVersion uint
+		}
+
+		if err := json.Unmarshal(trimmed, &header); err != nil {
+			return 0, err
+		}
+
+		if header.SchemaVersion == 0 {
+			return 0, errors.New("missing data file SchemaVersion")
+		}
+
+		return header.SchemaVersion, nil
+	default:
+		return 0, errors.New("unrecognized data file format")
+	}
+}
+
+// migrateV1ToV2 wraps the bare array of apps of version 1 in an envelope.
+func migrateV1ToV2(raw []byte) ([]byte, error) {
+	return json.Marshal(struct {
+		SchemaVersion uint
+		Apps          json.RawMessage
+	}{
+		SchemaVersion: 2,
+		Apps:          json.RawMessage(raw),
+	})
+}
@@ -0,0 +1,191 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// LintApps validates each of the given apps, like ValidateApps, and also checks
+// rules that apply across the whole collection of apps, such as duplicate
+// serial codes or titles.
+//
+// Problems found across multiple apps are reported on each of the apps
+// involved. Contradictory disc names are errors, rather than warnings, as the
+// data should be fixed.
+func LintApps(apps []App) ValidationReport {
+	validationErrs := validateEach(apps)
+
+	for _, validationErr := range validationErrs {
+		if validationErr == nil {
+			continue
+		}
+
+		for i, problem := range validationErr.Problems {
+			if problem.Field == "DiscNames" && errors.Is(problem.Err, ErrContradictory) {
+				validationErr.Problems[i].Severity = SeverityError
+			}
+		}
+	}
+
+	addProblem := func(i int, problem ValidationProblem) {
+		if validationErrs[i] == nil {
+			validationErrs[i] = &ValidationError{App: apps[i]}
+		}
+
+		validationErrs[i].Problems = append(validationErrs[i].Problems, problem)
+	}
+
+	// Duplicate serial codes
+	for _, indexes := range groupAppIndexes(apps, func(app App) string {
+		return app.SerialCode
+	}) {
+		for _, i := range indexes {
+			addProblem(i, ValidationProblem{
+				Field:    "SerialCode",
+				Value:    apps[i].SerialCode,
+				Err:      ErrDuplicate,
+				Detail:   fmt.Sprintf("shared by %d apps", len(indexes)),
+				Severity: SeverityError,
+			})
+		}
+	}
+
+	// Duplicate titles within a region, which would collide on the same
+	// config path
+	for _, indexes := range groupAppIndexes(apps, func(app App) string {
+		if app.Title == "" {
+			return ""
+		}
+
+		return fmt.Sprintf("%s\x00%s", app.Region, app.Title)
+	}) {
+		for _, i := range indexes {
+			addProblem(i, ValidationProblem{
+				Field:    "Title",
+				Value:    apps[i].Title,
+				Err:      ErrDuplicate,
+				Detail:   fmt.Sprintf("shared by %d apps in region %s, which would collide on the same config path", len(indexes), apps[i].Region),
+				Severity: SeverityError,
+			})
+		}
+	}
+
+	// Title variations shared with other apps, which would collide on the same
+	// alternative config paths, and so are left out of them
+	for _, collision := range titleVariationCollisions(apps) {
+		for _, i := range collision.indexes {
+			field := "TitleVariations"
+			detail := fmt.Sprintf("shared by %d apps, which would collide on the same alternative config path", len(collision.indexes))
+
+			if strings.EqualFold(apps[i].Title, collision.name) {
+				field = "Title"
+				detail = fmt.Sprintf("a title variation of %d other apps, which would collide on its config path", len(collision.indexes)-1)
+			}
+
+			addProblem(i, ValidationProblem{
+				Field:    field,
+				Value:    collision.name,
+				Err:      ErrDuplicate,
+				Detail:   detail,
+				Severity: SeverityWarning,
+			})
+		}
+	}
+
+	return buildValidationReport(apps, validationErrs)
+}
+
+// titleVariationCollision defines a name that more than one app is known by,
+// as a variation of the title of at least one of them.
+type titleVariationCollision struct {
+	name    string
+	indexes []int
+}
+
+// titleVariationCollisions returns the names that the given apps are known by,
+// with their title or their title variations, including the derived ones, that
+// are shared by more than one app as a variation, in the order that they were
+// first found.
+//
+// The names are compared case-insensitively, like config paths. Titles that
+// are only shared as titles aren't included, as they're duplicate titles.
+func titleVariationCollisions(apps []App) []titleVariationCollision {
+	indexesByName := make(map[string][]int)
+	namesByKey := make(map[string]string)
+	asVariation := make(map[string]bool)
+	var keys []string
+
+	add := func(i int, name string, variation bool) {
+		key := strings.ToLower(name)
+
+		if indexes := indexesByName[key]; len(indexes) > 0 && indexes[len(indexes)-1] == i {
+			return
+		}
+
+		if _, ok := indexesByName[key]; !ok {
+			keys = append(keys, key)
+			namesByKey[key] = name
+		}
+
+		indexesByName[key] = append(indexesByName[key], i)
+		asVariation[key] = asVariation[key] || variation
+	}
+
+	for i, app := range apps {
+		if app.Title == "" {
+			continue
+		}
+
+		expanded := app
+		expanded.TitleVariations = append([]string(nil), app.TitleVariations...)
+		expanded.ExpandTitleVariations()
+
+		add(i, app.Title, false)
+
+		for _, titleVariation := range expanded.TitleVariations {
+			add(i, titleVariation, true)
+		}
+	}
+
+	var collisions []titleVariationCollision
+	for _, key := range keys {
+		if indexes := indexesByName[key]; len(indexes) > 1 && asVariation[key] {
+			collisions = append(collisions, titleVariationCollision{name: namesByKey[key], indexes: indexes})
+		}
+	}
+
+	return collisions
+}
+
+// groupAppIndexes groups the indexes of the given apps by the key returned by
+// the given function, and returns only the groups with more than one app, in
+// the order that they were first found. Apps with an empty key are ignored.
+func groupAppIndexes(apps []App, key func(App) string) [][]int {
+	indexesByKey := make(map[string][]int)
+	var keys []string
+
+	for i, app := range apps {
+		k := key(app)
+		if k == "" {
+			continue
+		}
+
+		if _, ok := indexesByKey[k]; !ok {
+			keys = append(keys, k)
+		}
+
+		indexesByKey[k] = append(indexesByKey[k], i)
+	}
+
+	var groups [][]int
+	for _, k := range keys {
+		if indexes := indexesByKey[k]; len(indexes) > 1 {
+			groups = append(groups, indexes)
+		}
+	}
+
+	return groups
+}
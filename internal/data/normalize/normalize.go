@@ -5,14 +5,9 @@
 package normalize
 
 import (
-	"fmt"
-	"regexp"
 	"strings"
-)
 
-var (
-	regexSerialCodeLoose  = regexp.MustCompile(`([A-Za-z]+).*?(\d+)`)
-	regexSerialCodeStrict = regexp.MustCompile(`^([A-Z]{4})-(\d{5})$`)
+	"github.com/Rican7/psx-emu-conf/internal/data/serial"
 )
 
 // Region takes a region string and returns a normalized variant.
@@ -26,30 +21,30 @@ func Region(region string) string {
 }
 
 // SerialCode takes a serial code string and returns a normalized variant.
+//
+// Any re-release suffix (like the "GH" in "SLUS-00594GH") is kept, as a
+// re-release is a distinct release. See BaseSerialCode.
+//
+// An empty string is returned if the serial code can't be parsed.
 func SerialCode(serialCode string) string {
-	normalized := serialCode
-
-	normalized = strings.TrimSpace(normalized)
-	normalized = strings.ToUpper(normalized)
-
-	serialCodeMatches := regexSerialCodeLoose.FindStringSubmatch(normalized)
-	if len(serialCodeMatches) == 3 {
-		normalized = fmt.Sprintf("%s-%s", serialCodeMatches[1], serialCodeMatches[2])
-	}
-
-	if !regexSerialCodeStrict.MatchString(normalized) {
+	code, err := serial.Parse(serialCode)
+	if err != nil {
 		return ""
 	}
 
-	return normalized
+	return code.String()
 }
 
-// Title takes a title string and returns a normalized variant and any common
-// variations of that title.
-func Title(title string) (string, []string) {
-	normalized := title
-
-	normalized = strings.TrimSpace(normalized)
+// BaseSerialCode takes a serial code string and returns the normalized variant
+// of the serial code of its original release, without any re-release suffix,
+// so that a re-release can be looked up by its original release.
+//
+// An empty string is returned if the serial code can't be parsed.
+func BaseSerialCode(serialCode string) string {
+	code, err := serial.Parse(serialCode)
+	if err != nil {
+		return ""
+	}
 
-	return normalized, nil
+	return code.Base().String()
 }
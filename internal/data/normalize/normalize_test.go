@@ -0,0 +1,56 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package normalize
+
+import "testing"
+
+func TestSerialCode(t *testing.T) {
+	tests := []struct {
+		serialCode string
+		want       string
+		wantBase   string
+	}{
+		{"SCUS-94455", "SCUS-94455", "SCUS-94455"},
+		{" scus94455 ", "SCUS-94455", "SCUS-94455"},
+		{`cdrom:\SCUS_944.55;1`, "SCUS-94455", "SCUS-94455"},
+		{"SLUS-00594GH", "SLUS-00594GH", "SLUS-00594"},
+		{"slus_005.94", "SLUS-00594", "SLUS-00594"},
+		{"", "", ""},
+		{"not a serial", "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.serialCode, func(t *testing.T) {
+			if got := SerialCode(test.serialCode); got != test.want {
+				t.Errorf("SerialCode(%q) = %q, want %q", test.serialCode, got, test.want)
+			}
+
+			if got := BaseSerialCode(test.serialCode); got != test.wantBase {
+				t.Errorf("BaseSerialCode(%q) = %q, want %q", test.serialCode, got, test.wantBase)
+			}
+
+			// A normalized serial code is already normalized
+			if got := SerialCode(test.want); got != test.want {
+				t.Errorf("SerialCode(%q) = %q, want %q", test.want, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRegion(t *testing.T) {
+	tests := []struct {
+		region string
+		want   string
+	}{
+		{"NTSC-U", "NTSC-U"},
+		{" ntsc-j ", "NTSC-J"},
+		{"Pal", "PAL"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if got := Region(test.region); got != test.want {
+			t.Errorf("Region(%q) = %q, want %q", test.region, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package normalize
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	regexWhitespace = regexp.MustCompile(`\s+`)
+
+	// A regex for capturing a trailing tag, like the "(USA)" in
+	// "Final Fantasy VII (USA)", as used by the No-Intro and Redump naming
+	// conventions.
+	regexTitleTrailingTag = regexp.MustCompile(`\s*(\([^()]*\)|\[[^\[\]]*\])$`)
+
+	regexTitleTagLanguage = regexp.MustCompile(`^[A-Z][a-z](-[A-Z][a-z]+)?$`)
+	regexTitleTagRevision = regexp.MustCompile(`^(v\d+(\.\d+)*[a-z]?|Rev [0-9A-Z]+(\.\d+)?)$`)
+	regexTitleTagDisc     = regexp.MustCompile(`^Dis[ck] [0-9A-Z]+( of \d+)?$`)
+)
+
+// The region names used within region tags of the No-Intro and Redump naming
+// conventions.
+//
+// See:
+//  - https://wiki.no-intro.org/index.php?title=Naming_Convention
+//  - http://wiki.redump.org/index.php?title=Redump_Search_Parameters
+var titleTagRegions = map[string]struct{}{
+	"Asia":          {},
+	"Australia":     {},
+	"Austria":       {},
+	"Belgium":       {},
+	"Brazil":        {},
+	"Canada":        {},
+	"China":         {},
+	"Denmark":       {},
+	"Europe":        {},
+	"Finland":       {},
+	"France":        {},
+	"Germany":       {},
+	"Greece":        {},
+	"Hong Kong":     {},
+	"India":         {},
+	"Ireland":       {},
+	"Italy":         {},
+	"Japan":         {},
+	"Korea":         {},
+	"Latin America": {},
+	"Mexico":        {},
+	"Netherlands":   {},
+	"New Zealand":   {},
+	"Norway":        {},
+	"Poland":        {},
+	"Portugal":      {},
+	"Russia":        {},
+	"Scandinavia":   {},
+	"Singapore":     {},
+	"South Africa":  {},
+	"Spain":         {},
+	"Sweden":        {},
+	"Switzerland":   {},
+	"Taiwan":        {},
+	"UK":            {},
+	"USA":           {},
+	"World":         {},
+}
+
+// The articles that are commonly moved to the end of a title, or the end of
+// the first segment of a title, like "Legend of Dragoon, The".
+var titleArticles = []string{"The", "A", "An"}
+
+// Roman numerals that can be safely converted to and from Arabic numerals.
+//
+// NOTE: Some numerals are purposefully missing ("I", "V", "X"), as they're too
+// commonly used as actual words or letters in titles (ex: "Revolution X",
+// "I.Q - Intelligent Qube").
+var titleRomanNumerals = map[string]int{
+	"II":    2,
+	"III":   3,
+	"IV":    4,
+	"VI":    6,
+	"VII":   7,
+	"VIII":  8,
+	"IX":    9,
+	"XI":    11,
+	"XII":   12,
+	"XIII":  13,
+	"XIV":   14,
+	"XV":    15,
+	"XVI":   16,
+	"XVII":  17,
+	"XVIII": 18,
+	"XIX":   19,
+	"XX":    20,
+}
+
+// titleTag represents a trailing tag of a title.
+type titleTag struct {
+	raw      string // The tag, including its delimiters. Ex: "(USA)"
+	contents string // The contents of the tag. Ex: "USA"
+}
+
+func (t titleTag) isRegion() bool {
+	for _, part := range strings.Split(t.contents, ",") {
+		if _, ok := titleTagRegions[strings.TrimSpace(part)]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t titleTag) isLanguage() bool {
+	for _, part := range strings.Split(t.contents, ",") {
+		if !regexTitleTagLanguage.MatchString(strings.TrimSpace(part)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t titleTag) isRevision() bool {
+	return regexTitleTagRevision.MatchString(t.contents)
+}
+
+func (t titleTag) isDisc() bool {
+	return regexTitleTagDisc.MatchString(t.contents)
+}
+
+// Title takes a title string and returns a normalized variant and any common
+// variations of that title.
+//
+// The variations are the names that a dump of the title is likely to have,
+// such as the title without its revision, language, or disc tags, the title
+// without any tags at all, the title with its article moved, and the title
+// with its "&" or numerals written differently.
+func Title(title string) (string, []string) {
+	normalized := title
+
+	normalized = strings.TrimSpace(normalized)
+	normalized = regexWhitespace.ReplaceAllString(normalized, " ")
+
+	if normalized == "" {
+		return normalized, nil
+	}
+
+	return normalized, titleVariations(normalized)
+}
+
+// titleVariations returns the variations of the given normalized title, not
+// including the given title itself.
+func titleVariations(title string) []string {
+	name, tags := splitTitleTags(title)
+
+	if name == "" {
+		return nil
+	}
+
+	var nameVariations []string
+	nameVariationsSet := make(map[string]struct{})
+	addNameVariation := func(nameVariation string) {
+		if _, ok := nameVariationsSet[nameVariation]; !ok {
+			nameVariationsSet[nameVariation] = struct{}{}
+			nameVariations = append(nameVariations, nameVariation)
+		}
+	}
+
+	addNameVariation(name)
+
+	// Apply each transform to every variation found so far, so that the
+	// transforms are combined (ex: both moving the article AND converting the
+	// numerals).
+	for _, transform := range []func(string) string{
+		moveTitleArticle,
+		swapTitleAmpersand,
+		swapTitleNumerals,
+	} {
+		for _, nameVariation := range nameVariations {
+			if transformed := transform(nameVariation); transformed != "" {
+				addNameVariation(transformed)
+			}
+		}
+	}
+
+	var regionTags, mainTags, allTags []titleTag
+	for _, tag := range tags {
+		allTags = append(allTags, tag)
+
+		switch {
+		case tag.isRegion():
+			regionTags = append(regionTags, tag)
+			mainTags = append(mainTags, tag)
+		case tag.isRevision(), tag.isLanguage(), tag.isDisc():
+			// Skip
+		default:
+			mainTags = append(mainTags, tag)
+		}
+	}
+
+	tagSets := [][]titleTag{
+		allTags,
+		mainTags,
+		regionTags,
+		nil,
+	}
+
+	var variations []string
+	variationsSet := map[string]struct{}{title: {}}
+
+	for _, tagSet := range tagSets {
+		for _, nameVariation := range nameVariations {
+			variation := joinTitleTags(nameVariation, tagSet)
+
+			if _, ok := variationsSet[variation]; !ok {
+				variationsSet[variation] = struct{}{}
+				variations = append(variations, variation)
+			}
+		}
+	}
+
+	return variations
+}
+
+// splitTitleTags splits a title into its name and its trailing tags.
+func splitTitleTags(title string) (string, []titleTag) {
+	var tags []titleTag
+
+	name := title
+
+	for {
+		loc := regexTitleTrailingTag.FindStringSubmatchIndex(name)
+		if loc == nil || loc[0] == 0 {
+			break
+		}
+
+		raw := name[loc[2]:loc[3]]
+
+		tags = append([]titleTag{{raw: raw, contents: strings.TrimSpace(raw[1 : len(raw)-1])}}, tags...)
+
+		name = name[:loc[0]]
+	}
+
+	return name, tags
+}
+
+func joinTitleTags(name string, tags []titleTag) string {
+	parts := []string{name}
+
+	for _, tag := range tags {
+		parts = append(parts, tag.raw)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// moveTitleArticle moves the article of a title name either from the end of
+// the first segment of the name to the start ("Crow, The - City of Angels" to
+// "The Crow - City of Angels"), or vice versa.
+//
+// An empty string is returned if the name has no article to move.
+func moveTitleArticle(name string) string {
+	const segmentSeparator = " - "
+
+	segment := name
+	rest := ""
+
+	if i := strings.Index(name, segmentSeparator); i >= 0 {
+		segment = name[:i]
+		rest = name[i:]
+	}
+
+	for _, article := range titleArticles {
+		if suffix := ", " + article; strings.HasSuffix(segment, suffix) {
+			return article + " " + strings.TrimSuffix(segment, suffix) + rest
+		}
+
+		if prefix := article + " "; strings.HasPrefix(segment, prefix) && len(segment) > len(prefix) {
+			return strings.TrimPrefix(segment, prefix) + ", " + article + rest
+		}
+	}
+
+	return ""
+}
+
+// swapTitleAmpersand swaps any "&" in a title name with "and", or vice versa.
+//
+// An empty string is returned if the name has nothing to swap.
+func swapTitleAmpersand(name string) string {
+	switch {
+	case strings.Contains(name, " & "):
+		return strings.Replace(name, " & ", " and ", -1)
+	case strings.Contains(name, " and "):
+		return strings.Replace(name, " and ", " & ", -1)
+	}
+
+	return ""
+}
+
+// swapTitleNumerals swaps any Roman numerals in a title name with Arabic
+// numerals ("Final Fantasy VII" to "Final Fantasy 7"), or vice versa.
+//
+// An empty string is returned if the name has no numerals to swap.
+func swapTitleNumerals(name string) string {
+	words := strings.Split(name, " ")
+
+	var hasRoman, hasArabic bool
+	for _, word := range words {
+		if _, ok := titleRomanNumerals[word]; ok {
+			hasRoman = true
+		}
+		if _, ok := romanNumeralForArabic(word); ok {
+			hasArabic = true
+		}
+	}
+
+	swapped := make([]string, len(words))
+	for i, word := range words {
+		swapped[i] = word
+
+		switch {
+		case hasRoman:
+			if number, ok := titleRomanNumerals[word]; ok {
+				swapped[i] = strconv.Itoa(number)
+			}
+		case hasArabic:
+			if numeral, ok := romanNumeralForArabic(word); ok {
+				swapped[i] = numeral
+			}
+		}
+	}
+
+	if !hasRoman && !hasArabic {
+		return ""
+	}
+
+	return strings.Join(swapped, " ")
+}
+
+func romanNumeralForArabic(word string) (string, bool) {
+	// Don't convert numbers with leading zeros, like "007"
+	if strings.HasPrefix(word, "0") {
+		return "", false
+	}
+
+	number, err := strconv.Atoi(word)
+	if err != nil {
+		return "", false
+	}
+
+	for numeral, numeralNumber := range titleRomanNumerals {
+		if numeralNumber == number {
+			return numeral, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,129 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package normalize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTitle(t *testing.T) {
+	tests := []struct {
+		title          string
+		want           string
+		wantVariations []string
+	}{
+		{
+			title:          "",
+			want:           "",
+			wantVariations: nil,
+		},
+		{
+			title:          "Ape Escape",
+			want:           "Ape Escape",
+			wantVariations: nil,
+		},
+		{
+			title: "  Final   Fantasy VII (USA) (Disc 1) ",
+			want:  "Final Fantasy VII (USA) (Disc 1)",
+			wantVariations: []string{
+				"Final Fantasy 7 (USA) (Disc 1)",
+				"Final Fantasy VII (USA)",
+				"Final Fantasy 7 (USA)",
+				"Final Fantasy VII",
+				"Final Fantasy 7",
+			},
+		},
+		{
+			title: "Crow, The - City of Angels (USA)",
+			want:  "Crow, The - City of Angels (USA)",
+			wantVariations: []string{
+				"The Crow - City of Angels (USA)",
+				"Crow, The - City of Angels",
+				"The Crow - City of Angels",
+			},
+		},
+		{
+			title: "Tom & Jerry (Europe) (En,Fr,De) (Rev 1)",
+			want:  "Tom & Jerry (Europe) (En,Fr,De) (Rev 1)",
+			wantVariations: []string{
+				"Tom and Jerry (Europe) (En,Fr,De) (Rev 1)",
+				"Tom & Jerry (Europe)",
+				"Tom and Jerry (Europe)",
+				"Tom & Jerry",
+				"Tom and Jerry",
+			},
+		},
+		{
+			title: "Tobal 2 (Japan) (v1.1)",
+			want:  "Tobal 2 (Japan) (v1.1)",
+			wantVariations: []string{
+				"Tobal II (Japan) (v1.1)",
+				"Tobal 2 (Japan)",
+				"Tobal II (Japan)",
+				"Tobal 2",
+				"Tobal II",
+			},
+		},
+		{
+			// Numbers with leading zeros aren't numerals
+			title:          "007 Racing (USA)",
+			want:           "007 Racing (USA)",
+			wantVariations: []string{"007 Racing"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			got, gotVariations := Title(test.title)
+
+			if got != test.want {
+				t.Errorf("Title(%q) = %q, want %q", test.title, got, test.want)
+			}
+
+			if !reflect.DeepEqual(gotVariations, test.wantVariations) {
+				t.Errorf("Title(%q) variations = %q, want %q", test.title, gotVariations, test.wantVariations)
+			}
+		})
+	}
+}
+
+func TestMoveTitleArticle(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Crow, The - City of Angels", "The Crow - City of Angels"},
+		{"The Crow - City of Angels", "Crow, The - City of Angels"},
+		{"Legend of Dragoon, The", "The Legend of Dragoon"},
+		{"A Bug's Life", "Bug's Life, A"},
+		{"The", ""},
+		{"Theme Hospital", ""},
+		{"Ape Escape", ""},
+	}
+
+	for _, test := range tests {
+		if got := moveTitleArticle(test.name); got != test.want {
+			t.Errorf("moveTitleArticle(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSwapTitleNumerals(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Final Fantasy VII", "Final Fantasy 7"},
+		{"Final Fantasy 7", "Final Fantasy VII"},
+		{"Tobal II", "Tobal 2"},
+		{"007 Racing", ""},
+		{"Ape Escape", ""},
+	}
+
+	for _, test := range tests {
+		if got := swapTitleNumerals(test.name); got != test.want {
+			t.Errorf("swapTitleNumerals(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
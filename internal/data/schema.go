@@ -0,0 +1,273 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package data
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// SchemaID defines the identifier of the JSON Schema of the data format.
+	SchemaID = "https://github.com/Rican7/psx-emu-conf/blob/main/_data/data.schema.json"
+
+	schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+	schemaDefsRef = "#/$defs/"
+)
+
+// schemaEnumerator defines an interface for types that have a fixed set of
+// values, for describing them in the JSON Schema.
+type schemaEnumerator interface {
+	schemaEnum() []schemaEnumValue
+}
+
+// schemaEnumValue defines a single value of an enumerated type.
+type schemaEnumValue struct {
+	value       interface{}
+	description string
+	deprecated  bool
+}
+
+// Descriptions of the types, for the JSON Schema.
+var schemaTypeDescriptions = map[reflect.Type]string{
+	reflect.TypeOf(SourceInfo{}):     "A data source that the data was fetched from.",
+	reflect.TypeOf(App{}):            "A PlayStation software title.",
+	reflect.TypeOf(Region("")):       "The region of a PlayStation software title.",
+	reflect.TypeOf(FeatureSupport{}): "The PlayStation features and peripherals support matrix.",
+	reflect.TypeOf(AnalogSupport(0)): "The level of support of an \"Analog\" controller.",
+	reflect.TypeOf(RumbleSupport(0)): "The level of support of the \"rumble\" feature.",
+
+	reflect.TypeOf(PeripheralSupport(0)): "The level of support of a PlayStation peripheral.",
+}
+
+// Descriptions of the fields of the types, for the JSON Schema, keyed by the
+// type name and field name.
+var schemaFieldDescriptions = map[string]string{
+	"File.SchemaVersion": "The version of the data file format.",
+	"File.GeneratedAt":   "The time that the data was generated.",
+	"File.Sources":       "The data sources that the data was fetched from.",
+	"File.Apps":          "The PlayStation software titles.",
+
+	"SourceInfo.Name":     "The name of the data source.",
+	"SourceInfo.URL":      "The location of the data source.",
+	"SourceInfo.Revision": "The revision of the data source that the data was fetched from, if known.",
+
+	"App.Region":          "The region that the title was released in.",
+	"App.SerialCode":      "The serial code of the title, like \"SCUS-94455\".",
+	"App.Title":           "The title, in the No-Intro/Redump naming convention.",
+	"App.TitleVariations": "Other names that the title is commonly known by.",
+	"App.NumberOfDiscs":   "The number of discs of the title.",
+	"App.DiscNames":       "The names of each of the discs of the title.",
+	"App.FeatureSupport":  "The features and peripherals that the title supports.",
+
+	"FeatureSupport.AnalogSupport":        "The support of an \"Analog\" controller, such as the DualShock.",
+	"FeatureSupport.RumbleSupport":        "The support of controller vibration.",
+	"FeatureSupport.MultitapSupport":      "The support of the Multitap, for more than two controllers.",
+	"FeatureSupport.MouseSupport":         "The support of the PlayStation Mouse.",
+	"FeatureSupport.GunConSupport":        "The support of the Namco GunCon light gun.",
+	"FeatureSupport.JustifierSupport":     "The support of the Konami Justifier light gun.",
+	"FeatureSupport.NeGconSupport":        "The support of the Namco neGcon controller.",
+	"FeatureSupport.JogConSupport":        "The support of the Namco JogCon controller.",
+	"FeatureSupport.LinkCableSupport":     "The support of the Link Cable, for play across two consoles.",
+	"FeatureSupport.PocketStationSupport": "The support of the PocketStation memory card.",
+}
+
+func (Region) schemaEnum() []schemaEnumValue {
+	return []schemaEnumValue{
+		{RegionNTSCU, "North America.", false},
+		{RegionNTSCJ, "Japan and Asia.", false},
+		{RegionPAL, "Europe and Oceania.", false},
+	}
+}
+
+func (AnalogSupport) schemaEnum() []schemaEnumValue {
+	return withLegacyIntegerValues([]schemaEnumValue{
+		{AnalogSupportUnknown, "Unknown level of support.", false},
+		{AnalogSupportNo, "No support. Digital only.", false},
+		{AnalogSupportYes, "Supports analog controllers.", false},
+		{AnalogSupportRequired, "Analog controller is required.", false},
+	})
+}
+
+func (RumbleSupport) schemaEnum() []schemaEnumValue {
+	return withLegacyIntegerValues([]schemaEnumValue{
+		{RumbleSupportUnknown, "Unknown level of support.", false},
+		{RumbleSupportNo, "No support.", false},
+		{RumbleSupportYes, "Supports rumble.", false},
+	})
+}
+
+func (PeripheralSupport) schemaEnum() []schemaEnumValue {
+	return withLegacyIntegerValues([]schemaEnumValue{
+		{PeripheralSupportUnknown, "Unknown level of support.", false},
+		{PeripheralSupportNo, "No support.", false},
+		{PeripheralSupportYes, "Supports the peripheral.", false},
+		{PeripheralSupportRequired, "The peripheral is required.", false},
+	})
+}
+
+// withLegacyIntegerValues returns the given support level enum values, along
+// with their deprecated legacy integer forms, which are still accepted when
+// decoding.
+func withLegacyIntegerValues(values []schemaEnumValue) []schemaEnumValue {
+	for _, enumValue := range values {
+		values = append(values, schemaEnumValue{
+			value:       reflect.ValueOf(enumValue.value).Uint(),
+			description: fmt.Sprintf("Legacy integer form of %q.", enumValue.value),
+			deprecated:  true,
+		})
+	}
+
+	return values
+}
+
+// JSONSchema returns a JSON Schema that describes the data format, as a value
+// that can be encoded as JSON.
+//
+// The schema is generated from the data types themselves, so that it's always
+// in sync with them.
+func JSONSchema() map[string]interface{} {
+	generator := &schemaGenerator{
+		defs: make(map[string]interface{}),
+	}
+
+	schema := generator.definitionFor(reflect.TypeOf(File{}))
+
+	schema["$schema"] = schemaDialect
+	schema["$id"] = SchemaID
+	schema["title"] = "psx-emu-conf data"
+	schema["description"] = "A collection of PlayStation software titles, with metadata."
+	schema["required"] = []string{"SchemaVersion", "Apps"}
+	schema["$defs"] = generator.defs
+
+	// Only the current version is described
+	properties := schema["properties"].(map[string]interface{})
+	properties["SchemaVersion"].(map[string]interface{})["const"] = CurrentSchemaVersion
+
+	return schema
+}
+
+type schemaGenerator struct {
+	defs map[string]interface{}
+}
+
+// schemaFor returns the schema for the given type, referencing a definition if
+// the type is a named type of this package.
+func (g *schemaGenerator) schemaFor(t reflect.Type) map[string]interface{} {
+	if t.PkgPath() != reflect.TypeOf(App{}).PkgPath() || t.Name() == "" {
+		return g.definitionFor(t)
+	}
+
+	if _, ok := g.defs[t.Name()]; !ok {
+		// Reserve the name before generating, in case of recursive types
+		g.defs[t.Name()] = nil
+		g.defs[t.Name()] = g.definitionFor(t)
+	}
+
+	return map[string]interface{}{
+		"$ref": schemaDefsRef + t.Name(),
+	}
+}
+
+// definitionFor returns the full schema definition for the given type.
+func (g *schemaGenerator) definitionFor(t reflect.Type) map[string]interface{} {
+	definition := make(map[string]interface{})
+
+	if description, ok := schemaTypeDescriptions[t]; ok {
+		definition["description"] = description
+	}
+
+	if enumerator, ok := reflect.Zero(t).Interface().(schemaEnumerator); ok {
+		var values []interface{}
+
+		for _, enumValue := range enumerator.schemaEnum() {
+			value := map[string]interface{}{
+				"const":       enumValue.value,
+				"description": enumValue.description,
+			}
+
+			if enumValue.deprecated {
+				value["deprecated"] = true
+			}
+
+			values = append(values, value)
+		}
+
+		definition["oneOf"] = values
+
+		return definition
+	}
+
+	if t == reflect.TypeOf(time.Time{}) {
+		definition["type"] = "string"
+		definition["format"] = "date-time"
+
+		return definition
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		definition["type"] = "string"
+	case reflect.Bool:
+		definition["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		definition["type"] = "integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		definition["type"] = "integer"
+		definition["minimum"] = 0
+	case reflect.Float32, reflect.Float64:
+		definition["type"] = "number"
+	case reflect.Slice, reflect.Array:
+		definition["type"] = "array"
+		definition["items"] = g.schemaFor(t.Elem())
+	case reflect.Ptr:
+		return g.definitionFor(t.Elem())
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+
+			name, ok := jsonFieldName(field)
+			if !ok {
+				continue
+			}
+
+			property := g.schemaFor(field.Type)
+
+			if description, ok := schemaFieldDescriptions[fmt.Sprintf("%s.%s", t.Name(), field.Name)]; ok {
+				// Sibling keywords of a "$ref" are allowed in 2020-12
+				property["description"] = description
+			}
+
+			properties[name] = property
+		}
+
+		definition["type"] = "object"
+		definition["properties"] = properties
+	}
+
+	return definition
+}
+
+// jsonFieldName returns the name that a struct field is encoded as in JSON,
+// and whether the field is encoded at all.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if field.PkgPath != "" {
+		// Unexported
+		return "", false
+	}
+
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name, true
+	}
+
+	return field.Name, true
+}
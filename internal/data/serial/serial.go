@@ -0,0 +1,202 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+// Package serial provides mechanisms to parse and format PlayStation software
+// serial codes.
+//
+// A PlayStation software serial code is typically in the following format:
+//  - 4 letters (the "prefix"), a dash (`-`), and then 5 digits (the "number")
+//  - The 1st letter should always be an `S` for PlayStation titles
+//  - The 2nd letter should be either a `C` or `L`
+//  - The 3rd letter should be one of A, C, E, K, P, or U, denoting the region
+//  - The 4th letter should be one of D, M, S, T, or X
+//
+// On the discs themselves (and in their `SYSTEM.CNF` files), the serial code is
+// used as the name of the boot executable, in a format like `SCUS_944.55;1`.
+//
+// See: https://serialstation.com/serials/guide/ (though, SerialStation seems to
+// mix up "J" and "P"...)
+package serial
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	separator     = "-"
+	fileSeparator = "_"
+
+	numberMinLength = 3
+	numberMaxLength = 6
+
+	// The number of digits after the dot in the file name format.
+	fileNumberMinorLength = 2
+)
+
+// ErrSyntax is returned (wrapped) when a serial code can't be parsed.
+var ErrSyntax = errors.New("invalid serial code syntax")
+
+var (
+	// A regex for capturing the prefix, number, and suffix of a serial code,
+	// allowing for the different separators used in the different formats.
+	regexSerialCode = regexp.MustCompile(`^([A-Z]{3,4})[-_ ]?([0-9][0-9.]*[0-9])-?([A-Z]{0,3})$`)
+
+	// A regex for matching the ISO 9660 file version suffix, like the ";1" in
+	// `SCUS_944.55;1`.
+	regexFileVersion = regexp.MustCompile(`;\d+$`)
+)
+
+// Region defines the region of a serial code, as denoted by its prefix.
+type Region string
+
+// Available regions.
+const (
+	RegionUnknown Region = ""
+	RegionAmerica Region = "America"
+	RegionAsia    Region = "Asia"
+	RegionChina   Region = "China"
+	RegionEurope  Region = "Europe"
+	RegionJapan   Region = "Japan"
+	RegionKorea   Region = "Korea"
+)
+
+// Platform defines the platform of a serial code, as denoted by its prefix.
+type Platform string
+
+// Available platforms.
+const (
+	PlatformUnknown     Platform = ""
+	PlatformPlayStation Platform = "PlayStation"
+)
+
+// A map of the 3rd letter of a prefix to the region that it denotes.
+var prefixRegions = map[byte]Region{
+	'A': RegionAsia,
+	'C': RegionChina,
+	'E': RegionEurope,
+	'K': RegionKorea,
+	'P': RegionJapan,
+	'U': RegionAmerica,
+}
+
+// Code defines the structure of a PlayStation software serial code.
+type Code struct {
+	Prefix string // The letters of the code. Ex: "SCUS"
+	Number string // The digits of the code, including leading zeros. Ex: "94455"
+	Suffix string // Any trailing letters, denoting a re-release. Ex: "GH"
+}
+
+// Parse takes a serial code string, in any of its common formats, and returns
+// the parsed Code.
+//
+// The following formats (and case-insensitive variations) are supported:
+//  - `SCUS-94455`
+//  - `SCUS94455`
+//  - `SCUS_944.55`
+//  - `SCUS_944.55;1`
+//  - `cdrom:\SCUS_944.55;1`
+//  - `SLUS-00594GH`
+func Parse(serialCode string) (Code, error) {
+	normalized := serialCode
+
+	normalized = strings.TrimSpace(normalized)
+	normalized = strings.ToUpper(normalized)
+
+	// Strip any path, as found in the `BOOT` line of a `SYSTEM.CNF` file
+	if i := strings.LastIndexAny(normalized, `\/:`); i >= 0 {
+		normalized = normalized[i+1:]
+	}
+
+	normalized = regexFileVersion.ReplaceAllString(normalized, "")
+
+	matches := regexSerialCode.FindStringSubmatch(normalized)
+	if matches == nil {
+		return Code{}, fmt.Errorf("%w: %q", ErrSyntax, serialCode)
+	}
+
+	number := matches[2]
+
+	// Only allow a single dot, in the position used by the file name format
+	if strings.Contains(number, ".") {
+		if strings.Count(number, ".") > 1 ||
+			strings.Index(number, ".") != len(number)-fileNumberMinorLength-1 {
+			return Code{}, fmt.Errorf("%w: %q", ErrSyntax, serialCode)
+		}
+
+		number = strings.Replace(number, ".", "", 1)
+	}
+
+	if len(number) < numberMinLength || len(number) > numberMaxLength {
+		return Code{}, fmt.Errorf("%w: %q", ErrSyntax, serialCode)
+	}
+
+	code := Code{
+		Prefix: matches[1],
+		Number: number,
+		Suffix: matches[3],
+	}
+
+	return code, nil
+}
+
+// String returns the canonical string form of the serial code, like
+// `SCUS-94455`, including any suffix.
+//
+// The returned string can be parsed back into an equal Code.
+func (c Code) String() string {
+	return c.Prefix + separator + c.Number + c.Suffix
+}
+
+// FileName returns the serial code in the format used for the name of the boot
+// executable on a disc, like `SCUS_944.55`.
+//
+// The suffix isn't included, as it isn't used on discs.
+func (c Code) FileName() string {
+	number := c.Number
+
+	if len(number) > fileNumberMinorLength {
+		dot := len(number) - fileNumberMinorLength
+		number = number[:dot] + "." + number[dot:]
+	}
+
+	return c.Prefix + fileSeparator + number
+}
+
+// Base returns the serial code without any suffix.
+func (c Code) Base() Code {
+	c.Suffix = ""
+
+	return c
+}
+
+// Region returns the region denoted by the prefix of the serial code.
+func (c Code) Region() Region {
+	if c.Platform() != PlatformPlayStation {
+		return RegionUnknown
+	}
+
+	return prefixRegions[c.Prefix[2]]
+}
+
+// Platform returns the platform denoted by the prefix of the serial code.
+func (c Code) Platform() Platform {
+	if len(c.Prefix) != 4 || c.Prefix[0] != 'S' {
+		return PlatformUnknown
+	}
+
+	if !strings.ContainsRune("CL", rune(c.Prefix[1])) {
+		return PlatformUnknown
+	}
+
+	if _, ok := prefixRegions[c.Prefix[2]]; !ok {
+		return PlatformUnknown
+	}
+
+	if !strings.ContainsRune("DMSTX", rune(c.Prefix[3])) {
+		return PlatformUnknown
+	}
+
+	return PlatformPlayStation
+}
@@ -0,0 +1,156 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package serial
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		serialCode string
+		want       Code
+		wantString string
+	}{
+		{"SCUS-94455", Code{Prefix: "SCUS", Number: "94455"}, "SCUS-94455"},
+		{"SCUS94455", Code{Prefix: "SCUS", Number: "94455"}, "SCUS-94455"},
+		{"scus-94455", Code{Prefix: "SCUS", Number: "94455"}, "SCUS-94455"},
+		{"  SCUS-94455  ", Code{Prefix: "SCUS", Number: "94455"}, "SCUS-94455"},
+		{"SCUS 94455", Code{Prefix: "SCUS", Number: "94455"}, "SCUS-94455"},
+		{"SCUS_944.55", Code{Prefix: "SCUS", Number: "94455"}, "SCUS-94455"},
+		{"SCUS_944.55;1", Code{Prefix: "SCUS", Number: "94455"}, "SCUS-94455"},
+		{`cdrom:\SCUS_944.55;1`, Code{Prefix: "SCUS", Number: "94455"}, "SCUS-94455"},
+		{"cdrom:SLPS_011.99;1", Code{Prefix: "SLPS", Number: "01199"}, "SLPS-01199"},
+		{"SLUS-00594GH", Code{Prefix: "SLUS", Number: "00594", Suffix: "GH"}, "SLUS-00594GH"},
+		{"SLUS-00594-GH", Code{Prefix: "SLUS", Number: "00594", Suffix: "GH"}, "SLUS-00594GH"},
+		{"PBPX-95001", Code{Prefix: "PBPX", Number: "95001"}, "PBPX-95001"},
+		{"ESPM-70001", Code{Prefix: "ESPM", Number: "70001"}, "ESPM-70001"},
+		{"LSP-123", Code{Prefix: "LSP", Number: "123"}, "LSP-123"},
+		{"SLPM-860420", Code{Prefix: "SLPM", Number: "860420"}, "SLPM-860420"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.serialCode, func(t *testing.T) {
+			got, err := Parse(test.serialCode)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", test.serialCode, err)
+			}
+
+			if got != test.want {
+				t.Errorf("Parse(%q) = %#v, want %#v", test.serialCode, got, test.want)
+			}
+
+			if got.String() != test.wantString {
+				t.Errorf("Parse(%q).String() = %q, want %q", test.serialCode, got.String(), test.wantString)
+			}
+
+			// The canonical form must parse back into an equal code
+			reparsed, err := Parse(got.String())
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", got.String(), err)
+			}
+
+			if reparsed != got {
+				t.Errorf("Parse(%q) = %#v, want %#v", got.String(), reparsed, got)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"SCUS",
+		"94455",
+		"SCUS-",
+		"SCUS-12",
+		"SCUS-1234567",
+		"SCUS_9.44.55",
+		"SCUS_94.455",
+		"SC-94455",
+		"SCUSA-94455",
+		"SCUS-94455ABCD",
+		"SCUS-944X55",
+	}
+
+	for _, serialCode := range tests {
+		t.Run(serialCode, func(t *testing.T) {
+			got, err := Parse(serialCode)
+			if err == nil {
+				t.Fatalf("Parse(%q) = %#v, want error", serialCode, got)
+			}
+
+			if !errors.Is(err, ErrSyntax) {
+				t.Errorf("Parse(%q) returned error %v, want %v", serialCode, err, ErrSyntax)
+			}
+		})
+	}
+}
+
+func TestCodeFileName(t *testing.T) {
+	tests := []struct {
+		code Code
+		want string
+	}{
+		{Code{Prefix: "SCUS", Number: "94455"}, "SCUS_944.55"},
+		{Code{Prefix: "SLUS", Number: "00594", Suffix: "GH"}, "SLUS_005.94"},
+		{Code{Prefix: "LSP", Number: "123"}, "LSP_1.23"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.code.String(), func(t *testing.T) {
+			got := test.code.FileName()
+			if got != test.want {
+				t.Errorf("%#v.FileName() = %q, want %q", test.code, got, test.want)
+			}
+
+			// The file name must parse back into the code, without its suffix
+			reparsed, err := Parse(got)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", got, err)
+			}
+
+			if reparsed != test.code.Base() {
+				t.Errorf("Parse(%q) = %#v, want %#v", got, reparsed, test.code.Base())
+			}
+		})
+	}
+}
+
+func TestCodeRegionAndPlatform(t *testing.T) {
+	tests := []struct {
+		serialCode   string
+		wantRegion   Region
+		wantPlatform Platform
+	}{
+		{"SCUS-94455", RegionAmerica, PlatformPlayStation},
+		{"SLES-01234", RegionEurope, PlatformPlayStation},
+		{"SLPS-01199", RegionJapan, PlatformPlayStation},
+		{"SCPM-45025", RegionJapan, PlatformPlayStation},
+		{"SLKM-25001", RegionKorea, PlatformPlayStation},
+		{"SCAS-20001", RegionAsia, PlatformPlayStation},
+		{"SLCS-20001", RegionChina, PlatformPlayStation},
+		{"PBPX-95001", RegionUnknown, PlatformUnknown},
+		{"SXUS-12345", RegionUnknown, PlatformUnknown},
+		{"SLUZ-12345", RegionUnknown, PlatformUnknown},
+		{"LSP-123", RegionUnknown, PlatformUnknown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.serialCode, func(t *testing.T) {
+			code, err := Parse(test.serialCode)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", test.serialCode, err)
+			}
+
+			if got := code.Region(); got != test.wantRegion {
+				t.Errorf("Region() = %q, want %q", got, test.wantRegion)
+			}
+
+			if got := code.Platform(); got != test.wantPlatform {
+				t.Errorf("Platform() = %q, want %q", got, test.wantPlatform)
+			}
+		})
+	}
+}
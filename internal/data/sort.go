@@ -7,6 +7,10 @@ import "sort"
 type appsDefault []App
 
 // AppsDefault returns a sort.Interface for []App, using a default sort order.
+//
+// The default sort order is a total order across every field of an App, so
+// that sorting the same set of apps always results in the same ordering,
+// regardless of the order that the apps were in before sorting.
 func AppsDefault(apps []App) sort.Interface {
 	return appsDefault(apps)
 }
@@ -20,41 +24,93 @@ func (a appsDefault) Swap(i, j int) {
 }
 
 func (a appsDefault) Less(i, j int) bool {
-	if a[i].Region != a[j].Region {
-		if a[i].Region == "" {
-			return false
-		}
-		if a[j].Region == "" {
-			return true
-		}
-		if a[i].Region < a[j].Region {
-			return true
-		}
-		if a[i].Region > a[j].Region {
-			return false
-		}
+	return compareApps(a[i], a[j]) < 0
+}
+
+// compareApps compares two apps, field by field, and returns an integer
+// comparing the two: 0 if a == b, a negative number if a < b, and a positive
+// number if a > b.
+//
+// Empty values of the primary identifying fields (Region, SerialCode, Title)
+// are ordered last, so that incomplete apps are grouped at the end.
+func compareApps(a, b App) int {
+	if c := compareStringsEmptyLast(string(a.Region), string(b.Region)); c != 0 {
+		return c
 	}
 
-	if a[i].SerialCode != a[j].SerialCode {
-		if a[i].SerialCode == "" {
-			return false
-		}
-		if a[j].SerialCode == "" {
-			return true
-		}
-		if a[i].SerialCode < a[j].SerialCode {
-			return true
-		}
-		if a[i].SerialCode > a[j].SerialCode {
-			return false
+	if c := compareStringsEmptyLast(a.SerialCode, b.SerialCode); c != 0 {
+		return c
+	}
+
+	if c := compareStringsEmptyLast(a.Title, b.Title); c != 0 {
+		return c
+	}
+
+	if c := compareStringSlices(a.TitleVariations, b.TitleVariations); c != 0 {
+		return c
+	}
+
+	if c := compareUints(uint64(a.NumberOfDiscs), uint64(b.NumberOfDiscs)); c != 0 {
+		return c
+	}
+
+	if c := compareStringSlices(a.DiscNames, b.DiscNames); c != 0 {
+		return c
+	}
+
+	if c := compareUints(uint64(a.FeatureSupport.AnalogSupport), uint64(b.FeatureSupport.AnalogSupport)); c != 0 {
+		return c
+	}
+
+	if c := compareUints(uint64(a.FeatureSupport.RumbleSupport), uint64(b.FeatureSupport.RumbleSupport)); c != 0 {
+		return c
+	}
+
+	peripheralsA, peripheralsB := a.FeatureSupport.peripherals(), b.FeatureSupport.peripherals()
+	for i := range peripheralsA {
+		if c := compareUints(uint64(*peripheralsA[i].level), uint64(*peripheralsB[i].level)); c != 0 {
+			return c
 		}
 	}
 
-	if a[i].Title == "" {
-		return false
+	return 0
+}
+
+func compareStringsEmptyLast(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	case a < b:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func compareStringSlices(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
 	}
-	if a[j].Title == "" {
-		return true
+
+	return compareUints(uint64(len(a)), uint64(len(b)))
+}
+
+func compareUints(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
 	}
-	return a[i].Title < a[j].Title
 }
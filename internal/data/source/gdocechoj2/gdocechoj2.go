@@ -23,7 +23,10 @@ import (
 )
 
 const (
-	googleDocID = "1D4FKPOWCi11zhVvUcS8Bv4-IzyxH9MZRldugigTc59E"
+	sourceName = "PSX - General Game Info (Echoj2)"
+
+	googleDocID  = "1D4FKPOWCi11zhVvUcS8Bv4-IzyxH9MZRldugigTc59E"
+	googleDocURL = "https://docs.google.com/spreadsheets/d/" + googleDocID
 
 	sheetNTSCU = "NTSC-U"
 	sheetNTSCJ = "NTSC-J"
@@ -45,6 +48,16 @@ const (
 	vibrationLevelUnknown = 3
 )
 
+// The regions to fetch, in the order that they should be fetched.
+//
+// NOTE: This is kept separate from the range map, as the order of iteration of
+// a map isn't defined, and we want the fetched data to be in a stable order.
+var regions = []data.Region{
+	data.RegionNTSCU,
+	data.RegionNTSCJ,
+	data.RegionPAL,
+}
+
 // A map of regions to data cell ranges.
 var regionRangeMap = map[data.Region]string{
 	data.RegionNTSCU: fmt.Sprintf("%s!%s", sheetNTSCU, dataCellRange),
@@ -71,6 +84,16 @@ func New(googleAPIKey string) source.Source {
 	}
 }
 
+// Describe returns information about the source.
+//
+// NOTE: The revision isn't known, as the Sheets API doesn't expose revisions.
+func (s *src) Describe() data.SourceInfo {
+	return data.SourceInfo{
+		Name: sourceName,
+		URL:  googleDocURL,
+	}
+}
+
 func (s *src) Fetch(ctx context.Context) ([]data.App, error) {
 	var apps []data.App
 
@@ -79,7 +102,9 @@ func (s *src) Fetch(ctx context.Context) ([]data.App, error) {
 		return nil, err
 	}
 
-	for region, cellRange := range regionRangeMap {
+	for _, region := range regions {
+		cellRange := regionRangeMap[region]
+
 		dataResp, err := service.Spreadsheets.Get(googleDocID).
 			Ranges(cellRange).
 			Fields("sheets(data(rowData(values(hyperlink))))").
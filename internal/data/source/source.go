@@ -14,3 +14,10 @@ type Source interface {
 	// Fetch retrieves a list of apps, or returns an error.
 	Fetch(ctx context.Context) ([]data.App, error)
 }
+
+// Describer defines an interface for data sources that can describe themselves,
+// for recording where data was fetched from.
+type Describer interface {
+	// Describe returns information about the source.
+	Describe() data.SourceInfo
+}
@@ -0,0 +1,540 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format defines a format of a stream of apps.
+type Format string
+
+// Available formats.
+const (
+	// FormatJSON is the data file format: a JSON object envelope containing
+	// metadata and an array of the apps. When reading, a legacy bare JSON
+	// array of apps is also accepted.
+	FormatJSON Format = "json"
+
+	// FormatNDJSON is newline-delimited JSON, with one app per line and no
+	// metadata.
+	FormatNDJSON Format = "ndjson"
+
+	// FormatCSV is a flat CSV table, with a header row and one row per app,
+	// with a column for each feature. It's write-only.
+	FormatCSV Format = "csv"
+)
+
+// Formats returns the available formats.
+func Formats() []Format {
+	return []Format{FormatJSON, FormatNDJSON, FormatCSV}
+}
+
+// ParseFormat returns the Format of the given name.
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats() {
+		if string(format) == strings.ToLower(strings.TrimSpace(name)) {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown format %q", name)
+}
+
+// The number of bytes to peek at when detecting the format of a stream.
+const formatDetectionPeekSize = 4096
+
+// The indentation used when writing the JSON format.
+const jsonIndent = "  "
+
+// fileHeader defines the structure of the metadata of a data file, which is
+// everything except for the apps.
+type fileHeader struct {
+	SchemaVersion uint
+	GeneratedAt   *time.Time   `json:",omitempty"`
+	Sources       []SourceInfo `json:",omitempty"`
+}
+
+// AppReader reads apps one at a time from a stream, so that the entire stream
+// doesn't have to be held in memory.
+//
+// The format of the stream is detected automatically, and may be any of the
+// available formats: a data file (in the current or legacy array schema), or
+// newline-delimited JSON.
+//
+// Usage:
+//
+//	reader := data.NewAppReader(stream)
+//	for reader.Next() {
+//		app := reader.App()
+//	}
+//	if err := reader.Err(); err != nil {
+//	}
+type AppReader struct {
+	source  *bufio.Reader
+	decoder *json.Decoder
+
+	started  bool
+	finished bool
+	format   Format
+	inArray  bool // Whether the reader is within an array of apps.
+
+	header File
+	app    App
+	err    error
+}
+
+// NewAppReader returns an AppReader that reads from the given reader.
+func NewAppReader(reader io.Reader) *AppReader {
+	return &AppReader{
+		source: bufio.NewReaderSize(reader, formatDetectionPeekSize),
+	}
+}
+
+// Next reads the next app from the stream, returning true if an app was read,
+// and false if the end of the stream was reached or an error occurred.
+func (r *AppReader) Next() bool {
+	if r.finished {
+		return false
+	}
+
+	if !r.started {
+		r.started = true
+
+		if r.err = r.start(); r.err != nil {
+			r.finished = true
+			return false
+		}
+	}
+
+	hasNext, err := r.next()
+	if err != nil || !hasNext {
+		r.err = err
+		r.finished = true
+		return false
+	}
+
+	return true
+}
+
+// App returns the app most recently read by Next.
+func (r *AppReader) App() App {
+	return r.app
+}
+
+// Err returns the first error that occurred while reading, if any.
+func (r *AppReader) Err() error {
+	return r.err
+}
+
+// Format returns the detected format of the stream. It's only available after
+// the first call to Next.
+func (r *AppReader) Format() Format {
+	return r.format
+}
+
+// Header returns the metadata of the stream, without any apps.
+//
+// The metadata that precedes the apps in the stream is available after the
+// first call to Next, while any metadata that follows the apps is only
+// available after Next has returned false. Streams without metadata return an
+// empty File.
+func (r *AppReader) Header() File {
+	return r.header
+}
+
+// start detects the format of the stream and reads up to the first app.
+func (r *AppReader) start() error {
+	peeked, err := r.source.Peek(formatDetectionPeekSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return err
+	}
+
+	trimmed := bytes.TrimSpace(peeked)
+	if len(trimmed) == 0 {
+		return errors.New("empty data stream")
+	}
+
+	r.decoder = json.NewDecoder(r.source)
+
+	switch {
+	case trimmed[0] == '[':
+		// A legacy bare array of apps
+		r.format = FormatJSON
+		r.header.SchemaVersion = 1
+
+		return r.enterArray()
+	case trimmed[0] == '{' && isFileEnvelope(trimmed):
+		r.format = FormatJSON
+
+		if _, err := r.decoder.Token(); err != nil {
+			return err
+		}
+
+		return r.readHeaderUntilApps()
+	case trimmed[0] == '{':
+		r.format = FormatNDJSON
+
+		return nil
+	default:
+		return errors.New("unrecognized data stream format")
+	}
+}
+
+// next reads the next app from the stream.
+func (r *AppReader) next() (bool, error) {
+	switch r.format {
+	case FormatNDJSON:
+		var app App
+		if err := r.decoder.Decode(&app); err == io.EOF {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+
+		r.app = app
+
+		return true, nil
+	default:
+		if !r.inArray {
+			return false, nil
+		}
+
+		if !r.decoder.More() {
+			// Consume the closing bracket
+			if _, err := r.decoder.Token(); err != nil {
+				return false, err
+			}
+
+			r.inArray = false
+
+			if r.header.SchemaVersion > 1 {
+				// Read the rest of the envelope, and its closing brace
+				if err := r.readHeaderUntilApps(); err != nil {
+					return false, err
+				}
+			}
+
+			return false, nil
+		}
+
+		var app App
+		if err := r.decoder.Decode(&app); err != nil {
+			return false, err
+		}
+
+		r.app = app
+
+		return true, nil
+	}
+}
+
+// readHeaderUntilApps reads the keys and values of the envelope object, until
+// either the array of apps is entered or the end of the object is reached.
+func (r *AppReader) readHeaderUntilApps() error {
+	for r.decoder.More() {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("unexpected data file token %v", token)
+		}
+
+		switch key {
+		case "SchemaVersion":
+			err = r.decoder.Decode(&r.header.SchemaVersion)
+		case "GeneratedAt":
+			err = r.decoder.Decode(&r.header.GeneratedAt)
+		case "Sources":
+			err = r.decoder.Decode(&r.header.Sources)
+		case "Apps":
+			if err := r.checkSchemaVersion(); err != nil {
+				return err
+			}
+
+			return r.enterArray()
+		default:
+			// Skip unknown keys
+			var skipped json.RawMessage
+			err = r.decoder.Decode(&skipped)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// Consume the closing brace
+	_, err := r.decoder.Token()
+
+	return err
+}
+
+// checkSchemaVersion checks that the schema version of the envelope can be
+// streamed.
+//
+// Only the current schema version of the envelope can be streamed, as older
+// versions must be migrated as a whole document (with Decode).
+func (r *AppReader) checkSchemaVersion() error {
+	if r.header.SchemaVersion != CurrentSchemaVersion {
+		return fmt.Errorf("%w for streaming: %d (expected %d)", ErrUnsupportedVersion, r.header.SchemaVersion, CurrentSchemaVersion)
+	}
+
+	return nil
+}
+
+// enterArray reads the opening bracket of an array of apps.
+func (r *AppReader) enterArray() error {
+	token, err := r.decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token == nil {
+		// A null array of apps
+		return nil
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("expected an array of apps, found %v", token)
+	}
+
+	r.inArray = true
+
+	return nil
+}
+
+// isFileEnvelope returns true if the given (trimmed) raw JSON object looks like
+// a data file envelope, rather than an app, based on its first key.
+func isFileEnvelope(raw []byte) bool {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+
+	if _, err := decoder.Token(); err != nil {
+		return false
+	}
+
+	token, err := decoder.Token()
+	if err != nil {
+		return false
+	}
+
+	switch token {
+	case "SchemaVersion", "GeneratedAt", "Sources", "Apps":
+		return true
+	default:
+		return false
+	}
+}
+
+// AppWriter writes apps one at a time to a stream, so that the entire
+// collection of apps doesn't have to be held in memory.
+//
+// Close must be called after the last app is written, to complete the stream.
+type AppWriter struct {
+	writer    io.Writer
+	csvWriter *csv.Writer
+	format    Format
+	header    File
+
+	started bool
+	count   int
+	err     error
+}
+
+// NewAppWriter returns an AppWriter that writes to the given writer in the
+// given format.
+//
+// The metadata of the given header is written along with the apps, if the
+// format supports metadata. Any apps in the header are ignored.
+func NewAppWriter(writer io.Writer, format Format, header File) *AppWriter {
+	return &AppWriter{
+		writer: writer,
+		format: format,
+		header: header,
+	}
+}
+
+// Write writes an app to the stream.
+func (w *AppWriter) Write(app App) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	w.err = w.write(app)
+
+	return w.err
+}
+
+// Close completes the stream. It doesn't close the underlying writer.
+func (w *AppWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+
+	if !w.started {
+		if w.err = w.start(); w.err != nil {
+			return w.err
+		}
+	}
+
+	switch w.format {
+	case FormatJSON:
+		closing := "\n" + jsonIndent + "]\n}\n"
+		if w.count == 0 {
+			closing = "]\n}\n"
+		}
+
+		_, w.err = io.WriteString(w.writer, closing)
+	case FormatCSV:
+		w.csvWriter.Flush()
+		w.err = w.csvWriter.Error()
+	}
+
+	return w.err
+}
+
+func (w *AppWriter) start() error {
+	w.started = true
+
+	switch w.format {
+	case FormatJSON:
+		header := fileHeader{
+			SchemaVersion: CurrentSchemaVersion,
+			GeneratedAt:   w.header.GeneratedAt,
+			Sources:       w.header.Sources,
+		}
+
+		encodedHeader, err := json.MarshalIndent(header, "", jsonIndent)
+		if err != nil {
+			return err
+		}
+
+		// Re-open the encoded header object, to add the apps to it
+		encodedHeader = bytes.TrimSuffix(encodedHeader, []byte("\n}"))
+
+		_, err = fmt.Fprintf(w.writer, "%s,\n%s\"Apps\": [", encodedHeader, jsonIndent)
+
+		return err
+	case FormatNDJSON:
+		return nil
+	case FormatCSV:
+		w.csvWriter = csv.NewWriter(w.writer)
+
+		return w.csvWriter.Write(csvHeader())
+	default:
+		return fmt.Errorf("unsupported format %q", w.format)
+	}
+}
+
+func (w *AppWriter) write(app App) error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+
+	var encoded []byte
+	var err error
+
+	switch w.format {
+	case FormatJSON:
+		prefix := jsonIndent + jsonIndent
+
+		encoded, err = json.MarshalIndent(app, prefix, jsonIndent)
+		if err != nil {
+			return err
+		}
+
+		separator := ",\n"
+		if w.count == 0 {
+			separator = "\n"
+		}
+
+		encoded = append([]byte(separator+prefix), encoded...)
+	case FormatNDJSON:
+		encoded, err = json.Marshal(app)
+		if err != nil {
+			return err
+		}
+
+		encoded = append(encoded, '\n')
+	case FormatCSV:
+		if err := w.csvWriter.Write(csvRecord(app)); err != nil {
+			return err
+		}
+
+		w.count++
+
+		return nil
+	}
+
+	if _, err := w.writer.Write(encoded); err != nil {
+		return err
+	}
+
+	w.count++
+
+	return nil
+}
+
+// The separator used to join multiple values within a single CSV column.
+const csvValueSeparator = "|"
+
+// csvHeader returns the header row of the CSV format.
+//
+// Each field of the FeatureSupport is expanded into its own column, named with
+// its path (ex: "FeatureSupport.AnalogSupport").
+func csvHeader() []string {
+	header := []string{
+		"Region",
+		"SerialCode",
+		"Title",
+		"TitleVariations",
+		"NumberOfDiscs",
+		"DiscNames",
+	}
+
+	featureSupportType := reflect.TypeOf(FeatureSupport{})
+	for i := 0; i < featureSupportType.NumField(); i++ {
+		header = append(header, "FeatureSupport."+featureSupportType.Field(i).Name)
+	}
+
+	return header
+}
+
+// csvRecord returns the row of the CSV format for the given app.
+func csvRecord(app App) []string {
+	var numberOfDiscs string
+	if app.NumberOfDiscs > 0 {
+		numberOfDiscs = strconv.FormatUint(uint64(app.NumberOfDiscs), 10)
+	}
+
+	record := []string{
+		string(app.Region),
+		app.SerialCode,
+		app.Title,
+		strings.Join(app.TitleVariations, csvValueSeparator),
+		numberOfDiscs,
+		strings.Join(app.DiscNames, csvValueSeparator),
+	}
+
+	featureSupport := reflect.ValueOf(app.FeatureSupport)
+	for i := 0; i < featureSupport.NumField(); i++ {
+		record = append(record, fmt.Sprint(featureSupport.Field(i).Interface()))
+	}
+
+	return record
+}
@@ -0,0 +1,143 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// supportLevelNames defines the names of the levels of a type of support level,
+// as used in their string and JSON forms.
+//
+// Levels without a name are encoded in the legacy integer form, so that they
+// aren't lost, and can still be caught by validation.
+type supportLevelNames struct {
+	typeName string
+	names    map[uint]string
+}
+
+// The names of the analog support levels.
+var analogSupportNames = supportLevelNames{
+	typeName: "AnalogSupport",
+	names: map[uint]string{
+		uint(AnalogSupportUnknown):  "unknown",
+		uint(AnalogSupportNo):       "no",
+		uint(AnalogSupportYes):      "yes",
+		uint(AnalogSupportRequired): "required",
+	},
+}
+
+// The names of the rumble support levels.
+var rumbleSupportNames = supportLevelNames{
+	typeName: "RumbleSupport",
+	names: map[uint]string{
+		uint(RumbleSupportUnknown): "unknown",
+		uint(RumbleSupportNo):      "no",
+		uint(RumbleSupportYes):     "yes",
+	},
+}
+
+// The names of the peripheral support levels.
+var peripheralSupportNames = supportLevelNames{
+	typeName: "PeripheralSupport",
+	names: map[uint]string{
+		uint(PeripheralSupportUnknown):  "unknown",
+		uint(PeripheralSupportNo):       "no",
+		uint(PeripheralSupportYes):      "yes",
+		uint(PeripheralSupportRequired): "required",
+	},
+}
+
+// name returns the name of the given level.
+func (n supportLevelNames) name(level uint) string {
+	if name, ok := n.names[level]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("%s(%d)", n.typeName, level)
+}
+
+// marshal encodes the given level as its name.
+func (n supportLevelNames) marshal(level uint) ([]byte, error) {
+	if name, ok := n.names[level]; ok {
+		return json.Marshal(name)
+	}
+
+	return json.Marshal(level)
+}
+
+// unmarshal decodes a level into the given level, from its name, or from its
+// legacy integer form.
+func (n supportLevelNames) unmarshal(raw []byte, level *uint) error {
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		normalized := strings.ToLower(strings.TrimSpace(name))
+
+		for namedLevel, levelName := range n.names {
+			if levelName == normalized {
+				*level = namedLevel
+
+				return nil
+			}
+		}
+
+		return fmt.Errorf("invalid %s level %q", n.typeName, name)
+	}
+
+	// Fallback to the legacy integer form
+	if err := json.Unmarshal(raw, level); err != nil {
+		return fmt.Errorf("invalid %s level %s", n.typeName, raw)
+	}
+
+	return nil
+}
+
+// String returns the name of the support level.
+func (s AnalogSupport) String() string {
+	return analogSupportNames.name(uint(s))
+}
+
+// MarshalJSON encodes the support level as its name.
+func (s AnalogSupport) MarshalJSON() ([]byte, error) {
+	return analogSupportNames.marshal(uint(s))
+}
+
+// UnmarshalJSON decodes the support level from its name, or from its legacy
+// integer form.
+func (s *AnalogSupport) UnmarshalJSON(raw []byte) error {
+	return analogSupportNames.unmarshal(raw, (*uint)(s))
+}
+
+// String returns the name of the support level.
+func (s RumbleSupport) String() string {
+	return rumbleSupportNames.name(uint(s))
+}
+
+// MarshalJSON encodes the support level as its name.
+func (s RumbleSupport) MarshalJSON() ([]byte, error) {
+	return rumbleSupportNames.marshal(uint(s))
+}
+
+// UnmarshalJSON decodes the support level from its name, or from its legacy
+// integer form.
+func (s *RumbleSupport) UnmarshalJSON(raw []byte) error {
+	return rumbleSupportNames.unmarshal(raw, (*uint)(s))
+}
+
+// String returns the name of the support level.
+func (s PeripheralSupport) String() string {
+	return peripheralSupportNames.name(uint(s))
+}
+
+// MarshalJSON encodes the support level as its name.
+func (s PeripheralSupport) MarshalJSON() ([]byte, error) {
+	return peripheralSupportNames.marshal(uint(s))
+}
+
+// UnmarshalJSON decodes the support level from its name, or from its legacy
+// integer form.
+func (s *PeripheralSupport) UnmarshalJSON(raw []byte) error {
+	return peripheralSupportNames.unmarshal(raw, (*uint)(s))
+}
@@ -0,0 +1,98 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package data
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSupportLevelJSON(t *testing.T) {
+	tests := []struct {
+		json     string
+		want     FeatureSupport
+		wantJSON string
+	}{
+		{
+			json:     `{"AnalogSupport":"yes","RumbleSupport":"no","GunConSupport":"required"}`,
+			want:     FeatureSupport{AnalogSupport: AnalogSupportYes, RumbleSupport: RumbleSupportNo, GunConSupport: PeripheralSupportRequired},
+			wantJSON: `{"AnalogSupport":"yes","RumbleSupport":"no","GunConSupport":"required"}`,
+		},
+		{
+			json:     `{"AnalogSupport":" Required ","MouseSupport":"YES"}`,
+			want:     FeatureSupport{AnalogSupport: AnalogSupportRequired, MouseSupport: PeripheralSupportYes},
+			wantJSON: `{"AnalogSupport":"required","RumbleSupport":"unknown","MouseSupport":"yes"}`,
+		},
+		{
+			// The legacy integer form
+			json:     `{"AnalogSupport":2,"RumbleSupport":1,"NeGconSupport":3}`,
+			want:     FeatureSupport{AnalogSupport: AnalogSupportYes, RumbleSupport: RumbleSupportNo, NeGconSupport: PeripheralSupportRequired},
+			wantJSON: `{"AnalogSupport":"yes","RumbleSupport":"no","NeGconSupport":"required"}`,
+		},
+		{
+			// Levels without a name are kept in the legacy integer form
+			json:     `{"AnalogSupport":9,"RumbleSupport":7,"JogConSupport":8}`,
+			want:     FeatureSupport{AnalogSupport: 9, RumbleSupport: 7, JogConSupport: 8},
+			wantJSON: `{"AnalogSupport":9,"RumbleSupport":7,"JogConSupport":8}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.json, func(t *testing.T) {
+			var got FeatureSupport
+			if err := json.Unmarshal([]byte(test.json), &got); err != nil {
+				t.Fatalf("Unmarshal returned error: %v", err)
+			}
+
+			if got != test.want {
+				t.Errorf("Unmarshal = %+v, want %+v", got, test.want)
+			}
+
+			gotJSON, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("Marshal returned error: %v", err)
+			}
+
+			if string(gotJSON) != test.wantJSON {
+				t.Errorf("Marshal = %s, want %s", gotJSON, test.wantJSON)
+			}
+		})
+	}
+}
+
+func TestSupportLevelJSONInvalid(t *testing.T) {
+	tests := []string{
+		`{"AnalogSupport":"maybe"}`,
+		`{"RumbleSupport":"required"}`,
+		`{"GunConSupport":true}`,
+		`{"MouseSupport":-1}`,
+	}
+
+	for _, test := range tests {
+		var got FeatureSupport
+		if err := json.Unmarshal([]byte(test), &got); err == nil {
+			t.Errorf("Unmarshal(%s) = %+v, want error", test, got)
+		}
+	}
+}
+
+func TestSupportLevelString(t *testing.T) {
+	tests := []struct {
+		level interface{ String() string }
+		want  string
+	}{
+		{AnalogSupportUnknown, "unknown"},
+		{AnalogSupportRequired, "required"},
+		{AnalogSupport(9), "AnalogSupport(9)"},
+		{RumbleSupportYes, "yes"},
+		{RumbleSupport(7), "RumbleSupport(7)"},
+		{PeripheralSupportNo, "no"},
+		{PeripheralSupport(8), "PeripheralSupport(8)"},
+	}
+
+	for _, test := range tests {
+		if got := test.level.String(); got != test.want {
+			t.Errorf("String() = %q, want %q", got, test.want)
+		}
+	}
+}
@@ -3,44 +3,27 @@
 package data
 
 import (
-	"errors"
 	"fmt"
-	"regexp"
 	"sort"
+	"strings"
 
 	"github.com/Rican7/psx-emu-conf/internal/data/normalize"
+	"github.com/Rican7/psx-emu-conf/internal/data/serial"
 )
 
-var (
-	// regexSerialCode defines a regular expression for matching against valid
-	// serial codes.
-	//
-	// A valid PlayStation software serial code is in the following format:
-	//  - 4 letters, a dash (`-`), and then 5 digits
-	//  - The 1st letters should always be an `S` for PlayStation 1 titles
-	//  - The 2nd letter should be either a `C` or `L`
-	//  - The 3rd letter should be one of A, C, E, K, P, or U
-	//  - The 4th letter should be one of D, M, S, T, or X
-	//
-	// See: https://serialstation.com/serials/guide/
-	//  (though, SerialStation seems to mix up "J" and "P"...)
-	regexSerialCode = regexp.MustCompile(`^(S(C|L)(A|C|E|K|P|U)(D|M|S|T|X))-(\d{5})$`)
-)
+// The typical number of digits in the number of a serial code.
+const serialCodeNumberLength = 5
 
 // Normalize modifies an app in-place by performing some normalizations on the
 // data contained within the App.
+//
+// The variations derived from the title aren't added, so that they're never
+// stored in the data. See ExpandTitleVariations.
 func (a *App) Normalize() {
-	title, titleVariations := normalize.Title(a.Title)
+	title, _ := normalize.Title(a.Title)
 
-	titleVariationsSet := make(map[string]struct{})
-	var normalizedTitleVariations []string
-	for _, titleVariation := range append(a.TitleVariations, titleVariations...) {
-		if _, ok := titleVariationsSet[titleVariation]; !ok {
-			titleVariationsSet[titleVariation] = struct{}{}
-			normalizedTitleVariations = append(normalizedTitleVariations, titleVariation)
-		}
-	}
-	sort.Strings(normalizedTitleVariations)
+	a.Title = title
+	a.TitleVariations = uniqueTitleVariations(title, a.TitleVariations)
 
 	discNamesSet := make(map[string]struct{})
 	var normalizedDiscNames []string
@@ -54,39 +37,166 @@ func (a *App) Normalize() {
 
 	a.Region = Region(normalize.Region(string(a.Region)))
 	a.SerialCode = normalize.SerialCode(a.SerialCode)
-	a.Title = title
-	a.TitleVariations = normalizedTitleVariations
+
+	// Infer the region from the serial code, if it's missing
+	if a.Region == "" {
+		a.Region = RegionForSerialCode(a.SerialCode)
+	}
+
 	a.DiscNames = normalizedDiscNames
+
+	// A title that requires an input peripheral can't be played with a
+	// standard controller, so the controller features can't be supported.
+	for _, peripheral := range a.FeatureSupport.peripherals() {
+		if peripheral.input && *peripheral.level == PeripheralSupportRequired {
+			if a.FeatureSupport.AnalogSupport == AnalogSupportUnknown {
+				a.FeatureSupport.AnalogSupport = AnalogSupportNo
+			}
+
+			if a.FeatureSupport.RumbleSupport == RumbleSupportUnknown {
+				a.FeatureSupport.RumbleSupport = RumbleSupportNo
+			}
+		}
+	}
+}
+
+// ExpandTitleVariations modifies an app in-place by adding the common
+// variations of its title, that a dump of the app is likely to be named by.
+//
+// The variations are derived from the title, so they're only added when
+// they're needed, such as to locate configs, rather than stored in the data.
+func (a *App) ExpandTitleVariations() {
+	_, titleVariations := normalize.Title(a.Title)
+
+	// Copy the variations, rather than appending to those of any copy of the app
+	titleVariations = append(append([]string(nil), a.TitleVariations...), titleVariations...)
+
+	a.TitleVariations = uniqueTitleVariations(a.Title, titleVariations)
+}
+
+// uniqueTitleVariations returns the given title variations without duplicates
+// or the title itself, in sorted order.
+func uniqueTitleVariations(title string, titleVariations []string) []string {
+	// Seed the set with the title, so that the title isn't also a variation
+	titleVariationsSet := map[string]struct{}{title: {}}
+	var unique []string
+	for _, titleVariation := range titleVariations {
+		if _, ok := titleVariationsSet[titleVariation]; !ok {
+			titleVariationsSet[titleVariation] = struct{}{}
+			unique = append(unique, titleVariation)
+		}
+	}
+	sort.Strings(unique)
+
+	return unique
 }
 
 // Validate performs validations and returns an error if the data isn't valid.
-// The returned error should describe what is invalid about the data.
+//
+// Every problem found is collected into the returned error, which is always a
+// *ValidationError when non-nil. The problems may be only warnings, so callers
+// should check the severity (ex: with ValidationError.HasErrors) to determine
+// if the App is unusable.
 func (a *App) Validate() error {
+	var problems []ValidationProblem
+
+	addProblem := func(field string, value interface{}, err error, detail string, severity Severity) {
+		problems = append(problems, ValidationProblem{
+			Field:    field,
+			Value:    value,
+			Err:      err,
+			Detail:   detail,
+			Severity: severity,
+		})
+	}
+
 	switch a.Region {
 	case RegionNTSCU, RegionNTSCJ, RegionPAL:
 		// Valid
+	case "":
+		addProblem("Region", a.Region, ErrMissing, "", SeverityError)
 	default:
-		return errors.New("invalid Region")
+		addProblem("Region", a.Region, ErrInvalid, "", SeverityError)
 	}
 
-	if a.SerialCode != "" && !regexSerialCode.MatchString(a.SerialCode) {
-		fmt.Println(a.SerialCode)
-		return errors.New("invalid SerialCode")
+	if a.SerialCode != "" {
+		// A valid serial code must be parseable, in its canonical form, and
+		// be for the PlayStation platform.
+		serialCode, err := serial.Parse(a.SerialCode)
+		switch {
+		case err != nil:
+			addProblem("SerialCode", a.SerialCode, ErrInvalid, "unparseable", SeverityError)
+		case serialCode.String() != a.SerialCode:
+			addProblem("SerialCode", a.SerialCode, ErrInvalid, "not in canonical form", SeverityError)
+		case serialCode.Platform() != serial.PlatformPlayStation:
+			addProblem("SerialCode", a.SerialCode, ErrInvalid, "not a PlayStation serial code", SeverityError)
+		default:
+			if len(serialCode.Number) != serialCodeNumberLength {
+				addProblem("SerialCode", a.SerialCode, ErrUnusual, fmt.Sprintf("number isn't %d digits", serialCodeNumberLength), SeverityWarning)
+			}
+
+			if serialRegion := RegionForSerialCode(a.SerialCode); serialRegion != "" && a.Region != "" && serialRegion != a.Region {
+				addProblem("Region", a.Region, ErrContradictory, fmt.Sprintf("SerialCode denotes %s", serialRegion), SeverityWarning)
+			}
+		}
 	}
 
 	if a.Title == "" {
-		return errors.New("missing Title")
+		addProblem("Title", a.Title, ErrMissing, "", SeverityError)
+	}
+
+	// Each disc of a multi-disc title is named, and an unknown number of discs
+	// is a single disc
+	if a.NumberOfDiscs > 1 || len(a.DiscNames) > 1 {
+		numberOfDiscs := a.NumberOfDiscs
+		if numberOfDiscs == 0 {
+			numberOfDiscs = 1
+		}
+
+		if int(numberOfDiscs) != len(a.DiscNames) {
+			addProblem("DiscNames", a.DiscNames, ErrContradictory, fmt.Sprintf("%d disc names for %d discs", len(a.DiscNames), numberOfDiscs), SeverityWarning)
+		}
 	}
 
 	if a.FeatureSupport.AnalogSupport < AnalogSupportUnknown ||
 		a.FeatureSupport.AnalogSupport > AnalogSupportRequired {
-		return errors.New("invalid FeatureSupport.AnalogSupport level")
+		addProblem("FeatureSupport.AnalogSupport", a.FeatureSupport.AnalogSupport, ErrInvalid, "unknown level", SeverityError)
 	}
 
 	if a.FeatureSupport.RumbleSupport < RumbleSupportUnknown ||
 		a.FeatureSupport.RumbleSupport > RumbleSupportYes {
-		return errors.New("invalid FeatureSupport.RumbleSupport level")
+		addProblem("FeatureSupport.RumbleSupport", a.FeatureSupport.RumbleSupport, ErrInvalid, "unknown level", SeverityError)
 	}
 
-	return nil
+	var requiredInputs []string
+	if a.FeatureSupport.AnalogSupport == AnalogSupportRequired {
+		requiredInputs = append(requiredInputs, "AnalogSupport")
+	}
+
+	for _, peripheral := range a.FeatureSupport.peripherals() {
+		field := "FeatureSupport." + peripheral.name
+
+		if *peripheral.level < PeripheralSupportUnknown || *peripheral.level > PeripheralSupportRequired {
+			addProblem(field, *peripheral.level, ErrInvalid, "unknown level", SeverityError)
+			continue
+		}
+
+		if *peripheral.level == PeripheralSupportRequired && peripheral.input {
+			// Only one kind of input device can be required
+			if len(requiredInputs) > 0 {
+				addProblem(field, *peripheral.level, ErrContradictory, fmt.Sprintf("%s is also required", strings.Join(requiredInputs, ", ")), SeverityWarning)
+			}
+
+			requiredInputs = append(requiredInputs, peripheral.name)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return &ValidationError{
+		App:      *a,
+		Problems: problems,
+	}
 }
@@ -0,0 +1,62 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package data
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandTitleVariations(t *testing.T) {
+	tests := []struct {
+		name           string
+		app            App
+		wantNormalized []string
+		wantExpanded   []string
+	}{
+		{
+			name:           "derived variations",
+			app:            App{Title: "Tobal 2 (Japan) (v1.1)"},
+			wantNormalized: nil,
+			wantExpanded:   []string{"Tobal 2", "Tobal 2 (Japan)", "Tobal II", "Tobal II (Japan)", "Tobal II (Japan) (v1.1)"},
+		},
+		{
+			name:           "stored variations are kept, without duplicates",
+			app:            App{Title: "Ape Escape (USA)", TitleVariations: []string{"Saru Get You", "Ape Escape", "Ape Escape (USA)"}},
+			wantNormalized: []string{"Ape Escape", "Saru Get You"},
+			wantExpanded:   []string{"Ape Escape", "Saru Get You"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := test.app
+			app.Normalize()
+
+			// The derived variations aren't stored in the data
+			if !reflect.DeepEqual(app.TitleVariations, test.wantNormalized) {
+				t.Errorf("Normalize() variations = %q, want %q", app.TitleVariations, test.wantNormalized)
+			}
+
+			normalized := app
+			normalized.TitleVariations = make([]string, len(app.TitleVariations), len(app.TitleVariations)+10)
+			copy(normalized.TitleVariations, app.TitleVariations)
+
+			expanded := normalized
+			expanded.ExpandTitleVariations()
+
+			if !reflect.DeepEqual(expanded.TitleVariations, test.wantExpanded) {
+				t.Errorf("ExpandTitleVariations() variations = %q, want %q", expanded.TitleVariations, test.wantExpanded)
+			}
+
+			// The variations of copies of the app are left alone, even with
+			// spare capacity to append to
+			spare := normalized.TitleVariations[len(normalized.TitleVariations):cap(normalized.TitleVariations)]
+			for _, titleVariation := range spare {
+				if titleVariation != "" {
+					t.Errorf("ExpandTitleVariations() wrote %q into a copy of the app", titleVariation)
+				}
+			}
+		})
+	}
+}
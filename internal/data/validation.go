@@ -0,0 +1,285 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Validation problem reasons.
+//
+// These are wrapped by each ValidationProblem, so that they can be checked
+// with errors.Is.
+var (
+	ErrMissing       = errors.New("missing")
+	ErrInvalid       = errors.New("invalid")
+	ErrUnusual       = errors.New("unusual")
+	ErrContradictory = errors.New("contradictory")
+	ErrDuplicate     = errors.New("duplicate")
+)
+
+// Severity defines the severity of a validation problem.
+type Severity uint
+
+// Severity levels.
+const (
+	SeverityWarning Severity = iota + 1 // Suspicious, but still usable, data.
+	SeverityError                       // Invalid data.
+)
+
+// String returns the name of the severity level.
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("Severity(%d)", uint(s))
+	}
+}
+
+// ValidationProblem defines a single problem found when validating an App.
+type ValidationProblem struct {
+	Field    string      // The path of the field. Ex: "FeatureSupport.AnalogSupport"
+	Value    interface{} // The offending value of the field.
+	Err      error       // The reason for the problem. Ex: ErrInvalid
+	Detail   string      // An optional description of the problem.
+	Severity Severity
+}
+
+// Error returns a description of the problem.
+func (p ValidationProblem) Error() string {
+	value := fmt.Sprint(p.Value)
+	if p.Value != nil && reflect.TypeOf(p.Value).Kind() == reflect.String {
+		value = strconv.Quote(value)
+	}
+
+	message := fmt.Sprintf("%s %s (%s)", p.Err, p.Field, value)
+
+	if p.Detail != "" {
+		message += ": " + p.Detail
+	}
+
+	return message
+}
+
+// Unwrap returns the reason for the problem.
+func (p ValidationProblem) Unwrap() error {
+	return p.Err
+}
+
+// ValidationError defines an error that describes every problem found when
+// validating an App.
+type ValidationError struct {
+	App      App
+	Problems []ValidationProblem
+}
+
+// Error returns a description of the App and each of its problems.
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		problems[i] = fmt.Sprintf("[%s] %s", problem.Severity, problem.Error())
+	}
+
+	return fmt.Sprintf("app %s: %s", describeApp(e.App), strings.Join(problems, "; "))
+}
+
+// Severity returns the highest severity of the problems.
+func (e *ValidationError) Severity() Severity {
+	var severity Severity
+
+	for _, problem := range e.Problems {
+		if problem.Severity > severity {
+			severity = problem.Severity
+		}
+	}
+
+	return severity
+}
+
+// HasErrors returns true if any of the problems are errors, rather than just
+// warnings.
+func (e *ValidationError) HasErrors() bool {
+	return e.Severity() >= SeverityError
+}
+
+// describeApp returns a short, human-readable identification of an App.
+func describeApp(app App) string {
+	switch {
+	case app.Title != "" && app.SerialCode != "":
+		return fmt.Sprintf("%q (%s)", app.Title, app.SerialCode)
+	case app.Title != "":
+		return fmt.Sprintf("%q", app.Title)
+	case app.SerialCode != "":
+		return fmt.Sprintf("(%s)", app.SerialCode)
+	default:
+		return "(unidentified)"
+	}
+}
+
+// ValidationReport defines the results of validating a collection of apps.
+type ValidationReport struct {
+	Valid   []App // The valid apps, including those with only warnings.
+	Invalid []App // The invalid apps.
+
+	// Errors contains the validation errors of every app that had problems,
+	// including those with only warnings.
+	Errors []*ValidationError
+}
+
+// ValidateApps validates each of the given apps, and returns a report that
+// separates the valid apps from the invalid ones.
+func ValidateApps(apps []App) ValidationReport {
+	return buildValidationReport(apps, validateEach(apps))
+}
+
+// validateEach validates each of the given apps, returning a slice of the
+// validation errors that is index-aligned with the given apps. Apps without
+// problems have a nil error.
+func validateEach(apps []App) []*ValidationError {
+	validationErrs := make([]*ValidationError, len(apps))
+
+	for i := range apps {
+		validationErrs[i] = asValidationError(apps[i], apps[i].Validate())
+	}
+
+	return validationErrs
+}
+
+// asValidationError converts an error returned by validating the given app
+// into a *ValidationError, returning nil if there's no error.
+func asValidationError(app App, err error) *ValidationError {
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		// Treat unexpected errors as invalid, to be safe
+		validationErr = &ValidationError{
+			App: app,
+			Problems: []ValidationProblem{
+				{Err: err, Severity: SeverityError},
+			},
+		}
+	}
+
+	return validationErr
+}
+
+// buildValidationReport builds a report from the given apps and their
+// index-aligned validation errors.
+func buildValidationReport(apps []App, validationErrs []*ValidationError) ValidationReport {
+	var report ValidationReport
+
+	for i, validationErr := range validationErrs {
+		if validationErr == nil {
+			report.Valid = append(report.Valid, apps[i])
+			continue
+		}
+
+		report.Errors = append(report.Errors, validationErr)
+
+		if validationErr.HasErrors() {
+			report.Invalid = append(report.Invalid, apps[i])
+		} else {
+			report.Valid = append(report.Valid, apps[i])
+		}
+	}
+
+	return report
+}
+
+// Summary returns a summary of the report, with counts of the apps and of each
+// type of problem.
+func (r ValidationReport) Summary() *ValidationSummary {
+	summary := &ValidationSummary{
+		NumValid: len(r.Valid) - (len(r.Errors) - len(r.Invalid)),
+	}
+
+	for _, validationErr := range r.Errors {
+		summary.Record(validationErr.App, validationErr)
+	}
+
+	return summary
+}
+
+// ValidationSummary defines a summary of the validation of a collection of
+// apps, with counts of the apps and of each type of problem.
+//
+// A summary can be built incrementally, such as while streaming apps, without
+// holding onto the apps themselves.
+type ValidationSummary struct {
+	NumValid   int // The number of valid apps, without any warnings.
+	NumWarned  int // The number of valid apps, with only warnings.
+	NumInvalid int // The number of invalid apps.
+
+	problemCounts map[string]int
+}
+
+// Record records the result of validating the given app, given the error
+// returned by App.Validate, and returns whether the app is valid.
+func (s *ValidationSummary) Record(app App, err error) bool {
+	validationErr := asValidationError(app, err)
+
+	if validationErr == nil {
+		s.NumValid++
+		return true
+	}
+
+	if s.problemCounts == nil {
+		s.problemCounts = make(map[string]int)
+	}
+
+	for _, problem := range validationErr.Problems {
+		problemType := fmt.Sprintf("[%s] %s %s", problem.Severity, problem.Err, problem.Field)
+
+		s.problemCounts[problemType]++
+	}
+
+	if validationErr.HasErrors() {
+		s.NumInvalid++
+		return false
+	}
+
+	s.NumWarned++
+
+	return true
+}
+
+// String returns a human-readable form of the summary.
+func (s *ValidationSummary) String() string {
+	var builder strings.Builder
+
+	numApps := s.NumValid + s.NumWarned + s.NumInvalid
+
+	fmt.Fprintf(&builder, "validated %d apps: %d valid (%d with warnings), %d invalid\n", numApps, s.NumValid+s.NumWarned, s.NumWarned, s.NumInvalid)
+
+	var problemTypes []string
+	for problemType := range s.problemCounts {
+		problemTypes = append(problemTypes, problemType)
+	}
+
+	counts := s.problemCounts
+
+	sort.Slice(problemTypes, func(i, j int) bool {
+		if counts[problemTypes[i]] != counts[problemTypes[j]] {
+			return counts[problemTypes[i]] > counts[problemTypes[j]]
+		}
+
+		return problemTypes[i] < problemTypes[j]
+	})
+
+	for _, problemType := range problemTypes {
+		fmt.Fprintf(&builder, "  %5d  %s\n", counts[problemType], problemType)
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,197 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+// Package diff provides mechanisms to compare texts, line by line.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext defines the default number of unchanged lines shown around
+// the changed lines of a unified diff.
+const DefaultContext = 3
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// op defines a single operation of an edit script, that turns one list of
+// lines into another.
+type op struct {
+	kind opKind
+	line string
+
+	// The (0-based) positions in each list of lines, at the time of the op
+	aIndex int
+	bIndex int
+}
+
+// Unified returns a unified diff of the given texts, with the given labels and
+// number of context lines, or an empty string if the texts are equal.
+func Unified(aLabel, bLabel string, a, b string, context int) string {
+	if a == b {
+		return ""
+	}
+
+	ops := editScript(splitLines(a), splitLines(b))
+
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "--- %s\n", aLabel)
+	fmt.Fprintf(&builder, "+++ %s\n", bLabel)
+
+	for _, hunk := range hunks(ops, context) {
+		writeHunk(&builder, hunk)
+	}
+
+	return builder.String()
+}
+
+// splitLines splits a text into its lines, without their line endings.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// editScript returns the shortest edit script that turns a into b, based on
+// their longest common subsequence.
+func editScript(a, b []string) []op {
+	// lengths[i][j] is the length of the LCS of a[i:] and b[j:]
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, op{opEqual, a[i], i, j})
+			i++
+			j++
+		case j >= len(b) || (i < len(a) && lengths[i+1][j] >= lengths[i][j+1]):
+			ops = append(ops, op{opDelete, a[i], i, j})
+			i++
+		default:
+			ops = append(ops, op{opInsert, b[j], i, j})
+			j++
+		}
+	}
+
+	return ops
+}
+
+// hunks groups the given ops into hunks of changes, with the given number of
+// unchanged lines of context around them.
+func hunks(ops []op, context int) [][]op {
+	var result [][]op
+
+	start, end := -1, -1
+
+	for i, o := range ops {
+		if o.kind == opEqual {
+			continue
+		}
+
+		// Split the hunk when the unchanged lines between the changes are more
+		// than the context of both
+		if start >= 0 && i-end-1 > 2*context {
+			result = append(result, ops[start:min(end+context+1, len(ops))])
+			start = -1
+		}
+
+		if start < 0 {
+			start = max(i-context, 0)
+		}
+
+		end = i
+	}
+
+	if start >= 0 {
+		result = append(result, ops[start:min(end+context+1, len(ops))])
+	}
+
+	return result
+}
+
+func writeHunk(builder *strings.Builder, hunk []op) {
+	var aCount, bCount int
+
+	for _, o := range hunk {
+		if o.kind != opInsert {
+			aCount++
+		}
+
+		if o.kind != opDelete {
+			bCount++
+		}
+	}
+
+	fmt.Fprintf(builder, "@@ -%s +%s @@\n", hunkRange(hunk[0].aIndex, aCount), hunkRange(hunk[0].bIndex, bCount))
+
+	for _, o := range hunk {
+		switch o.kind {
+		case opEqual:
+			fmt.Fprintf(builder, " %s\n", o.line)
+		case opDelete:
+			fmt.Fprintf(builder, "-%s\n", o.line)
+		case opInsert:
+			fmt.Fprintf(builder, "+%s\n", o.line)
+		}
+	}
+}
+
+// hunkRange formats the range of lines of a hunk, from the (0-based) index of
+// its first line.
+//
+// An empty range refers to the line before it, as in GNU diff.
+func hunkRange(index int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", index)
+	}
+
+	if count == 1 {
+		return fmt.Sprintf("%d", index+1)
+	}
+
+	return fmt.Sprintf("%d,%d", index+1, count)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,153 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package diff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	const twelveLines = "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+
+	tests := []struct {
+		name    string
+		a       string
+		b       string
+		context int
+		want    string
+	}{
+		{
+			name:    "equal",
+			a:       "a\nb\n",
+			b:       "a\nb\n",
+			context: DefaultContext,
+			want:    "",
+		},
+		{
+			name:    "created",
+			a:       "",
+			b:       "a\nb\n",
+			context: DefaultContext,
+			want: "--- a\n+++ b\n" +
+				"@@ -0,0 +1,2 @@\n" +
+				"+a\n" +
+				"+b\n",
+		},
+		{
+			name:    "removed",
+			a:       "a\nb\n",
+			b:       "",
+			context: DefaultContext,
+			want: "--- a\n+++ b\n" +
+				"@@ -1,2 +0,0 @@\n" +
+				"-a\n" +
+				"-b\n",
+		},
+		{
+			name:    "deleted and inserted",
+			a:       "1\n2\n3\n",
+			b:       "1\n3\n4\n",
+			context: DefaultContext,
+			want: "--- a\n+++ b\n" +
+				"@@ -1,3 +1,3 @@\n" +
+				" 1\n" +
+				"-2\n" +
+				" 3\n" +
+				"+4\n",
+		},
+		{
+			name:    "distant changes in separate hunks",
+			a:       twelveLines,
+			b:       "1\nX\n3\n4\n5\n6\n7\n8\n9\n10\nY\n12\n",
+			context: DefaultContext,
+			want: "--- a\n+++ b\n" +
+				"@@ -1,5 +1,5 @@\n" +
+				" 1\n" +
+				"-2\n" +
+				"+X\n" +
+				" 3\n" +
+				" 4\n" +
+				" 5\n" +
+				"@@ -8,5 +8,5 @@\n" +
+				" 8\n" +
+				" 9\n" +
+				" 10\n" +
+				"-11\n" +
+				"+Y\n" +
+				" 12\n",
+		},
+		{
+			name:    "nearby changes in a single hunk",
+			a:       "1\n2\n3\n4\n5\n6\n7\n8\n",
+			b:       "1\nX\n3\n4\n5\n6\nY\n8\n",
+			context: DefaultContext,
+			want: "--- a\n+++ b\n" +
+				"@@ -1,8 +1,8 @@\n" +
+				" 1\n" +
+				"-2\n" +
+				"+X\n" +
+				" 3\n" +
+				" 4\n" +
+				" 5\n" +
+				" 6\n" +
+				"-7\n" +
+				"+Y\n" +
+				" 8\n",
+		},
+		{
+			name:    "changes separated by twice the context in a single hunk",
+			a:       twelveLines,
+			b:       "1\nX\n3\n4\n5\n6\n7\n8\nY\n10\n11\n12\n",
+			context: DefaultContext,
+			want: "--- a\n+++ b\n" +
+				"@@ -1,12 +1,12 @@\n" +
+				" 1\n" +
+				"-2\n" +
+				"+X\n" +
+				" 3\n" +
+				" 4\n" +
+				" 5\n" +
+				" 6\n" +
+				" 7\n" +
+				" 8\n" +
+				"-9\n" +
+				"+Y\n" +
+				" 10\n" +
+				" 11\n" +
+				" 12\n",
+		},
+		{
+			name:    "less context",
+			a:       twelveLines,
+			b:       "1\nX\n3\n4\n5\n6\n7\n8\n9\n10\nY\n12\n",
+			context: 1,
+			want: "--- a\n+++ b\n" +
+				"@@ -1,3 +1,3 @@\n" +
+				" 1\n" +
+				"-2\n" +
+				"+X\n" +
+				" 3\n" +
+				"@@ -10,3 +10,3 @@\n" +
+				" 10\n" +
+				"-11\n" +
+				"+Y\n" +
+				" 12\n",
+		},
+		{
+			name:    "no context",
+			a:       twelveLines,
+			b:       "1\n2\n3\n4\nX\n6\n7\n8\n9\n10\n11\n12\n",
+			context: 0,
+			want: "--- a\n+++ b\n" +
+				"@@ -5 +5 @@\n" +
+				"-5\n" +
+				"+X\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Unified("a", "b", test.a, test.b, test.context); got != test.want {
+				t.Errorf("Unified = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -27,3 +27,43 @@ type Configurator interface {
 	EmulatorName() string
 	Configure(writer io.Writer, app data.App) error
 }
+
+// Filter defines an interface for emulation configurators that only configure
+// an emulator for some apps, such as those that need a special input device.
+type Filter interface {
+	Accepts(app data.App) bool
+}
+
+// OptionsOverrider defines an interface for emulation configurators that allow
+// the raw options of an emulator to be overridden.
+type OptionsOverrider interface {
+	// OwnsOption returns true if the raw option of the given key belongs to
+	// the configured emulator.
+	OwnsOption(key string) bool
+
+	// ConfigureWithOptions configures the emulator for the given app, like
+	// Configure, with the given raw options replacing those that would be
+	// written.
+	ConfigureWithOptions(writer io.Writer, app data.App, options map[string]string) error
+}
+
+// Merger defines an interface for emulation configurators whose configs can be
+// merged into existing configs, so that the settings that aren't configured
+// are kept.
+//
+// The managed settings that are no longer generated are only removed if prune
+// is true, as when the existing config was written by the configurator, so
+// that settings set by hand are never removed.
+type Merger interface {
+	Merge(writer io.Writer, existing io.Reader, generated io.Reader, prune bool) error
+}
+
+// Pruner defines an interface for emulation configurators that can remove the
+// settings that they manage from existing configs, for the apps that they no
+// longer configure.
+//
+// Only configs that were written by the configurator should be pruned, so that
+// settings set by hand are never removed.
+type Pruner interface {
+	Prune(writer io.Writer, existing io.Reader) error
+}
@@ -0,0 +1,272 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+// Package encoding provides encoders that render emulator options in the config
+// file formats of various emulators and frontends.
+package encoding
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Rican7/psx-emu-conf/internal/emuconf"
+)
+
+// Encoder defines an interface for encoders of emulator options.
+type Encoder interface {
+	Encode(writer io.Writer, options emuconf.Options) error
+}
+
+// Group defines the options of a single emulator, among several.
+type Group struct {
+	// The name of the emulator.
+	Name string
+
+	Options emuconf.Options
+}
+
+// GroupEncoder defines an interface for encoders that encode the options of
+// several emulators as a single document, rather than one after another.
+type GroupEncoder interface {
+	Encoder
+
+	EncodeGroups(writer io.Writer, groups []Group) error
+}
+
+// EncoderFunc defines a function that implements the Encoder interface.
+type EncoderFunc func(writer io.Writer, options emuconf.Options) error
+
+// Encode calls the function.
+func (f EncoderFunc) Encode(writer io.Writer, options emuconf.Options) error {
+	return f(writer, options)
+}
+
+// The available encoders, keyed by their name.
+var encoders = map[string]Encoder{
+	"retroarch": RetroArch,
+	"ini":       INI,
+	"mednafen":  Mednafen,
+	"json":      JSON,
+}
+
+// Names returns the names of the available encoders, in sorted order.
+func Names() []string {
+	var names []string
+
+	for name := range encoders {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// ForName returns the encoder of the given name.
+func ForName(name string) (Encoder, error) {
+	encoder, ok := encoders[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("unknown encoding %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+
+	return encoder, nil
+}
+
+// RetroArch encodes options in the RetroArch config format, as used by core
+// option files and configuration override files:
+//
+//	key = "value"
+//
+// NOTE: RetroArch has no escape sequences in its config files, so the values
+// are simply wrapped in double quotes.
+var RetroArch = lineEncoder(func(option emuconf.Option) string {
+	return fmt.Sprintf("%s = \"%s\"", option.Key, option.Value)
+})
+
+// INI encodes options in a sectionless INI format:
+//
+//	key=value
+var INI = lineEncoder(func(option emuconf.Option) string {
+	return fmt.Sprintf("%s=%s", option.Key, option.Value)
+})
+
+// Mednafen encodes options in the Mednafen config format, as the settings of its
+// PSX module:
+//
+//	psx.input.analog_mode_ct 1
+//
+// The options of the Beetle PSX cores, which are ports of Mednafen, are encoded
+// as the Mednafen settings that they correspond to. Any other options have no
+// Mednafen equivalent, so they're left out.
+//
+// See:
+//  - https://mednafen.github.io/documentation/#Section_settings
+//  - https://mednafen.github.io/documentation/psx.html
+var Mednafen = EncoderFunc(func(writer io.Writer, options emuconf.Options) error {
+	w := bufio.NewWriter(writer)
+
+	for _, option := range options {
+		setting, ok := mednafenSettingOf(option.Key)
+		if !ok {
+			continue
+		}
+
+		value, ok := setting.values[option.Value]
+		if !ok {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "%s %s\n", setting.name, value); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+})
+
+// mednafenSetting defines the Mednafen setting of an option, and its values for
+// each of the values of the option.
+type mednafenSetting struct {
+	name   string
+	values map[string]string
+}
+
+// The values of the Mednafen settings of the options that are toggled.
+var mednafenToggleValues = map[string]string{
+	"enabled":  "1",
+	"disabled": "0",
+}
+
+// The prefixes of the option keys of the Beetle PSX cores, with the longest
+// first, as they overlap.
+var mednafenOptionKeyPrefixes = []string{"beetle_psx_hw_", "beetle_psx_"}
+
+// The Mednafen settings of the options of the Beetle PSX cores, keyed by the
+// option key without its prefix.
+//
+// See:
+//  - https://github.com/libretro/beetle-psx-libretro/blob/master/libretro_core_options.h
+var mednafenSettings = map[string]mednafenSetting{
+	"analog_toggle":         {name: "psx.input.analog_mode_ct", values: mednafenToggleValues},
+	"enable_multitap_port1": {name: "psx.input.pport1.multitap", values: mednafenToggleValues},
+	"enable_multitap_port2": {name: "psx.input.pport2.multitap", values: mednafenToggleValues},
+}
+
+// mednafenSettingOf returns the Mednafen setting of the option of the given
+// key, if it has one.
+func mednafenSettingOf(key string) (mednafenSetting, bool) {
+	for _, prefix := range mednafenOptionKeyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			setting, ok := mednafenSettings[strings.TrimPrefix(key, prefix)]
+
+			return setting, ok
+		}
+	}
+
+	return mednafenSetting{}, false
+}
+
+// JSON encodes options as a JSON object, with the keys in the order of the
+// options. The options of several emulators are encoded as a single object,
+// keyed by the emulator name.
+var JSON GroupEncoder = jsonEncoder{}
+
+// jsonEncoder implements the JSON encoder.
+type jsonEncoder struct{}
+
+// Encode encodes the given options as a JSON object.
+func (jsonEncoder) Encode(writer io.Writer, options emuconf.Options) error {
+	w := bufio.NewWriter(writer)
+
+	if err := writeJSONObject(w, options, ""); err != nil {
+		return err
+	}
+
+	w.WriteString("\n")
+
+	return w.Flush()
+}
+
+// EncodeGroups encodes the given groups of options as a JSON object of JSON
+// objects, keyed by the group names.
+func (jsonEncoder) EncodeGroups(writer io.Writer, groups []Group) error {
+	w := bufio.NewWriter(writer)
+
+	w.WriteString("{")
+
+	for i, group := range groups {
+		name, err := json.Marshal(group.Name)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			w.WriteString(",")
+		}
+
+		fmt.Fprintf(w, "\n  %s: ", name)
+
+		if err := writeJSONObject(w, group.Options, "  "); err != nil {
+			return err
+		}
+	}
+
+	if len(groups) > 0 {
+		w.WriteString("\n")
+	}
+
+	w.WriteString("}\n")
+
+	return w.Flush()
+}
+
+// writeJSONObject writes the given options as a JSON object, with each line
+// after the first indented by the given indent.
+func writeJSONObject(w *bufio.Writer, options emuconf.Options, indent string) error {
+	w.WriteString("{")
+
+	for i, option := range options {
+		key, err := json.Marshal(option.Key)
+		if err != nil {
+			return err
+		}
+
+		value, err := json.Marshal(option.Value)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			w.WriteString(",")
+		}
+
+		fmt.Fprintf(w, "\n%s  %s: %s", indent, key, value)
+	}
+
+	if len(options) > 0 {
+		fmt.Fprintf(w, "\n%s", indent)
+	}
+
+	w.WriteString("}")
+
+	return nil
+}
+
+// lineEncoder returns an encoder that writes each option on its own line, in
+// the format of the given function.
+func lineEncoder(format func(option emuconf.Option) string) Encoder {
+	return EncoderFunc(func(writer io.Writer, options emuconf.Options) error {
+		w := bufio.NewWriter(writer)
+
+		for _, option := range options {
+			if _, err := fmt.Fprintln(w, format(option)); err != nil {
+				return err
+			}
+		}
+
+		return w.Flush()
+	})
+}
@@ -0,0 +1,151 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package encoding
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Rican7/psx-emu-conf/internal/emuconf"
+)
+
+func TestEncoders(t *testing.T) {
+	options := emuconf.Options{
+		{Key: "beetle_psx_hw_analog_toggle", Value: "enabled"},
+		{Key: "beetle_psx_enable_multitap_port2", Value: "disabled"},
+		{Key: "beetle_psx_gun_cursor", Value: "cross"},
+		{Key: "pcsx_rearmed_pad1type", Value: "dualshock"},
+	}
+
+	tests := []struct {
+		name    string
+		encoder Encoder
+		options emuconf.Options
+		want    string
+	}{
+		{
+			name:    "retroarch",
+			encoder: RetroArch,
+			options: options,
+			want: "beetle_psx_hw_analog_toggle = \"enabled\"\n" +
+				"beetle_psx_enable_multitap_port2 = \"disabled\"\n" +
+				"beetle_psx_gun_cursor = \"cross\"\n" +
+				"pcsx_rearmed_pad1type = \"dualshock\"\n",
+		},
+		{
+			name:    "ini",
+			encoder: INI,
+			options: options,
+			want: "beetle_psx_hw_analog_toggle=enabled\n" +
+				"beetle_psx_enable_multitap_port2=disabled\n" +
+				"beetle_psx_gun_cursor=cross\n" +
+				"pcsx_rearmed_pad1type=dualshock\n",
+		},
+		{
+			name:    "mednafen",
+			encoder: Mednafen,
+			options: options,
+			want: "psx.input.analog_mode_ct 1\n" +
+				"psx.input.pport2.multitap 0\n",
+		},
+		{
+			name:    "mednafen with unknown values",
+			encoder: Mednafen,
+			options: emuconf.Options{{Key: "beetle_psx_analog_toggle", Value: "sometimes"}},
+			want:    "",
+		},
+		{
+			name:    "json",
+			encoder: JSON,
+			options: options,
+			want: "{\n" +
+				"  \"beetle_psx_hw_analog_toggle\": \"enabled\",\n" +
+				"  \"beetle_psx_enable_multitap_port2\": \"disabled\",\n" +
+				"  \"beetle_psx_gun_cursor\": \"cross\",\n" +
+				"  \"pcsx_rearmed_pad1type\": \"dualshock\"\n" +
+				"}\n",
+		},
+		{
+			name:    "json without options",
+			encoder: JSON,
+			options: nil,
+			want:    "{}\n",
+		},
+		{
+			name:    "json escapes",
+			encoder: JSON,
+			options: emuconf.Options{{Key: "a\"b", Value: "c\\d"}},
+			want:    "{\n  \"a\\\"b\": \"c\\\\d\"\n}\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var encoded bytes.Buffer
+			if err := test.encoder.Encode(&encoded, test.options); err != nil {
+				t.Fatalf("Encode returned error: %v", err)
+			}
+
+			if encoded.String() != test.want {
+				t.Errorf("Encode wrote %q, want %q", encoded.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestJSONEncodeGroups(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups []Group
+		want   string
+	}{
+		{
+			name:   "no groups",
+			groups: nil,
+			want:   "{}\n",
+		},
+		{
+			name: "several groups",
+			groups: []Group{
+				{Name: "Beetle PSX", Options: emuconf.Options{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}}},
+				{Name: "Empty", Options: nil},
+			},
+			want: "{\n" +
+				"  \"Beetle PSX\": {\n" +
+				"    \"a\": \"1\",\n" +
+				"    \"b\": \"2\"\n" +
+				"  },\n" +
+				"  \"Empty\": {}\n" +
+				"}\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var encoded bytes.Buffer
+			if err := JSON.EncodeGroups(&encoded, test.groups); err != nil {
+				t.Fatalf("EncodeGroups returned error: %v", err)
+			}
+
+			if encoded.String() != test.want {
+				t.Errorf("EncodeGroups wrote %q, want %q", encoded.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestForName(t *testing.T) {
+	for _, name := range Names() {
+		if _, err := ForName(name); err != nil {
+			t.Errorf("ForName(%q) returned error: %v", name, err)
+		}
+	}
+
+	if _, err := ForName(" Mednafen "); err != nil {
+		t.Errorf("ForName(%q) returned error: %v", " Mednafen ", err)
+	}
+
+	if encoder, err := ForName("yaml"); err == nil {
+		t.Errorf("ForName(%q) = %v, want error", "yaml", encoder)
+	}
+}
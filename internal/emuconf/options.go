@@ -0,0 +1,65 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package emuconf
+
+import "github.com/Rican7/psx-emu-conf/internal/data"
+
+// Option defines a single emulator option.
+type Option struct {
+	Key   string
+	Value string // The raw value, without any quoting of an encoding.
+}
+
+// Options defines an ordered set of emulator options, with unique keys.
+type Options []Option
+
+// OptionsConfigurator defines an interface for emulation configurators that
+// determine a structured set of options for a given app, that can then be
+// encoded, compared, or merged.
+type OptionsConfigurator interface {
+	EmulatorName() string
+	Options(app data.App) (Options, error)
+}
+
+// Get returns the value of the option of the given key, and whether the option
+// is set.
+func (o Options) Get(key string) (string, bool) {
+	for _, option := range o {
+		if option.Key == key {
+			return option.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Set sets the value of the option of the given key, replacing the existing
+// option in place, or appending it if it isn't set.
+func (o *Options) Set(key, value string) {
+	for i, option := range *o {
+		if option.Key == key {
+			(*o)[i].Value = value
+			return
+		}
+	}
+
+	*o = append(*o, Option{Key: key, Value: value})
+}
+
+// Keys returns the keys of the options, in order.
+func (o Options) Keys() []string {
+	keys := make([]string, 0, len(o))
+
+	for _, option := range o {
+		keys = append(keys, option.Key)
+	}
+
+	return keys
+}
+
+// Merge sets each of the given options, in order.
+func (o *Options) Merge(other Options) {
+	for _, option := range other {
+		o.Set(option.Key, option.Value)
+	}
+}
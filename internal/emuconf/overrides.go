@@ -0,0 +1,142 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package emuconf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/Rican7/psx-emu-conf/internal/data"
+	"github.com/Rican7/psx-emu-conf/internal/data/normalize"
+)
+
+// Override defines a manual override of the data and the emulator options of a
+// specific app, to patch errors locally.
+type Override struct {
+	// A partial app, in the JSON form of a data.App, whose fields replace
+	// those of the app.
+	App json.RawMessage `json:",omitempty"`
+
+	// Raw emulator options, keyed by the option name, that replace those that
+	// would be written for the app. Ex: "beetle_psx_hw_analog_toggle"
+	Options map[string]string `json:",omitempty"`
+}
+
+// Apply returns a copy of the given app with the fields of the override
+// applied.
+func (o Override) Apply(app data.App) (data.App, error) {
+	if len(o.App) == 0 {
+		return app, nil
+	}
+
+	// Unmarshalling over an existing value only replaces the fields present
+	if err := json.Unmarshal(o.App, &app); err != nil {
+		return app, err
+	}
+
+	return app, nil
+}
+
+// Overrides defines a set of manual overrides, keyed by the serial code or the
+// title of the apps that they apply to.
+//
+// The overrides are loaded from a JSON object, like:
+//
+//	{
+//	  "SCUS-94455": {
+//	    "Options": {"beetle_psx_hw_analog_toggle": "enabled"}
+//	  },
+//	  "Crash Bandicoot (USA)": {
+//	    "App": {"FeatureSupport": {"AnalogSupport": "no"}}
+//	  }
+//	}
+type Overrides struct {
+	overrides map[string]Override
+	matched   map[string]bool
+}
+
+// LoadOverrides reads a JSON object of overrides from the given reader, and
+// validates them.
+func LoadOverrides(reader io.Reader) (*Overrides, error) {
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+
+	var raw map[string]Override
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	overrides := &Overrides{
+		overrides: make(map[string]Override, len(raw)),
+		matched:   make(map[string]bool),
+	}
+
+	for key, override := range raw {
+		// Validate the partial app up front, so that it can't fail later
+		if len(override.App) > 0 {
+			appDecoder := json.NewDecoder(bytes.NewReader(override.App))
+			appDecoder.DisallowUnknownFields()
+
+			if err := appDecoder.Decode(&data.App{}); err != nil {
+				return nil, fmt.Errorf("override %q: %w", key, err)
+			}
+		}
+
+		// Normalize serial code keys, so that they match normalized apps
+		if serialCode := normalize.SerialCode(key); serialCode != "" {
+			key = serialCode
+		}
+
+		if _, ok := overrides.overrides[key]; ok {
+			return nil, fmt.Errorf("duplicate override %q", key)
+		}
+
+		overrides.overrides[key] = override
+	}
+
+	return overrides, nil
+}
+
+// Lookup returns the override of the given app, and whether there is one.
+//
+// An override keyed by the serial code of the app takes precedence over one
+// keyed by the serial code of its original release, for a re-release, which
+// takes precedence over one keyed by its title.
+func (o *Overrides) Lookup(app data.App) (Override, bool) {
+	if o == nil {
+		return Override{}, false
+	}
+
+	for _, key := range []string{app.SerialCode, normalize.BaseSerialCode(app.SerialCode), app.Title} {
+		if override, ok := o.overrides[key]; ok && key != "" {
+			o.matched[key] = true
+
+			return override, true
+		}
+	}
+
+	return Override{}, false
+}
+
+// Unmatched returns the keys of the overrides that haven't matched any app in a
+// Lookup, in sorted order.
+func (o *Overrides) Unmatched() []string {
+	if o == nil {
+		return nil
+	}
+
+	var keys []string
+
+	for key := range o.overrides {
+		if !o.matched[key] {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
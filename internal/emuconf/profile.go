@@ -0,0 +1,186 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package emuconf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Rican7/psx-emu-conf/internal/data"
+)
+
+const (
+	// DefaultPorts defines the default number of controller ports to configure.
+	DefaultPorts = 2
+
+	// MaxPorts defines the maximum number of controller ports that can be
+	// configured, with a Multitap in each of the two ports of the console.
+	MaxPorts = 8
+
+	// The number of controller ports of the console, and of a Multitap.
+	consolePorts  = 2
+	multitapPorts = 4
+)
+
+// Profile defines a set of user preferences that control the decisions of the
+// emulation configurators, so that different sets of configs can be produced
+// from the same data.
+//
+// The zero value is a valid profile, with the default preferences.
+type Profile struct {
+	// The support levels to assume for apps with an unknown level of support.
+	UnknownAnalogSupport data.AnalogSupport `json:",omitempty"`
+	UnknownRumbleSupport data.RumbleSupport `json:",omitempty"`
+
+	// Whether to prefer a DualShock over an analog controller, even for apps
+	// that don't support rumble. This never takes precedence over a light gun
+	// or another peripheral that an app supports.
+	PreferDualShock bool `json:",omitempty"`
+
+	// Whether to enable (true) or disable (false) rumble, or to leave it up to
+	// the emulator (nil).
+	Rumble *bool `json:",omitempty"`
+
+	// The number of controller ports to configure, or 0 for DefaultPorts.
+	// Ports beyond the two of the console are only configured for apps that
+	// support the Multitap, with a Multitap plugged in for them.
+	Ports uint `json:",omitempty"`
+
+	// The input devices to prefer, from the most to the least preferred, when
+	// an app supports them.
+	PreferredDevices []data.InputDevice `json:",omitempty"`
+}
+
+// LoadProfile reads a JSON profile from the given reader, and validates it.
+func LoadProfile(reader io.Reader) (Profile, error) {
+	var profile Profile
+
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&profile); err != nil {
+		return Profile{}, err
+	}
+
+	for i, device := range profile.PreferredDevices {
+		parsed, err := data.ParseInputDevice(string(device))
+		if err != nil {
+			return Profile{}, err
+		}
+
+		profile.PreferredDevices[i] = parsed
+	}
+
+	if err := profile.Validate(); err != nil {
+		return Profile{}, err
+	}
+
+	return profile, nil
+}
+
+// Validate returns an error if the profile isn't valid.
+func (p Profile) Validate() error {
+	if p.UnknownAnalogSupport > data.AnalogSupportRequired {
+		return fmt.Errorf("invalid profile UnknownAnalogSupport %s", p.UnknownAnalogSupport)
+	}
+
+	if p.UnknownRumbleSupport > data.RumbleSupportYes {
+		return fmt.Errorf("invalid profile UnknownRumbleSupport %s", p.UnknownRumbleSupport)
+	}
+
+	if p.Ports > MaxPorts {
+		return fmt.Errorf("invalid profile Ports %d (max %d)", p.Ports, MaxPorts)
+	}
+
+	for _, device := range p.PreferredDevices {
+		if device == "" {
+			return errors.New("empty profile PreferredDevices entry")
+		}
+	}
+
+	return nil
+}
+
+// NumberOfPorts returns the number of controller ports to configure.
+func (p Profile) NumberOfPorts() int {
+	if p.Ports == 0 {
+		return DefaultPorts
+	}
+
+	return int(p.Ports)
+}
+
+// AppPorts returns the number of controller ports to configure for the given
+// app: those of the profile, limited to the ports of the console unless the app
+// supports the Multitap.
+func (p Profile) AppPorts(app data.App) int {
+	ports := p.NumberOfPorts()
+
+	switch app.FeatureSupport.MultitapSupport {
+	case data.PeripheralSupportYes, data.PeripheralSupportRequired:
+		return ports
+	}
+
+	if ports > consolePorts {
+		return consolePorts
+	}
+
+	return ports
+}
+
+// Multitaps returns whether a Multitap is plugged into each of the two ports of
+// the console, for the controller ports to configure for the given app.
+//
+// The first port of the console gets a Multitap before the second, so the
+// second only gets one for more than five controller ports.
+func (p Profile) Multitaps(app data.App) (port1 bool, port2 bool) {
+	ports := p.AppPorts(app)
+
+	return ports > consolePorts, ports > multitapPorts+consolePorts-1
+}
+
+// RumbleEnabled returns whether rumble should be enabled, and whether the
+// profile has a preference at all.
+func (p Profile) RumbleEnabled() (enabled bool, ok bool) {
+	if p.Rumble == nil {
+		return false, false
+	}
+
+	return *p.Rumble, true
+}
+
+// InputDevices returns the input devices to prefer, from the most to the least
+// preferred, when an app supports them.
+//
+// The DualShock is included if it's preferred, but as a controller it's only
+// preferred over the other controllers. See data.FeatureSupport.InputDevices.
+func (p Profile) InputDevices() []data.InputDevice {
+	devices := append([]data.InputDevice(nil), p.PreferredDevices...)
+
+	if p.PreferDualShock {
+		devices = append(devices, data.InputDeviceDualShock)
+	}
+
+	return devices
+}
+
+// Resolve returns a copy of the given app, with the unknown levels of support
+// replaced by the levels assumed by the profile, and with rumble support
+// removed if rumble is disabled.
+func (p Profile) Resolve(app data.App) data.App {
+	if app.FeatureSupport.AnalogSupport == data.AnalogSupportUnknown {
+		app.FeatureSupport.AnalogSupport = p.UnknownAnalogSupport
+	}
+
+	if app.FeatureSupport.RumbleSupport == data.RumbleSupportUnknown {
+		app.FeatureSupport.RumbleSupport = p.UnknownRumbleSupport
+	}
+
+	if enabled, ok := p.RumbleEnabled(); ok && !enabled {
+		app.FeatureSupport.RumbleSupport = data.RumbleSupportNo
+	}
+
+	return app
+}
@@ -3,7 +3,6 @@
 package retroarch
 
 import (
-	"fmt"
 	"io"
 
 	"github.com/Rican7/psx-emu-conf/internal/data"
@@ -20,10 +19,31 @@ const (
 const (
 	beetlePSXConfigAnalogToggleKey = "beetle_psx_analog_toggle"
 
-	beetlePSXConfigAnalogToggleValueDisabled = `"disabled"`
-	beetlePSXConfigAnalogToggleValueEnabled  = `"enabled"`
+	beetlePSXConfigAnalogToggleValueDisabled = "disabled"
+	beetlePSXConfigAnalogToggleValueEnabled  = "enabled"
+
+	beetlePSXConfigGunInputModeKey           = "beetle_psx_gun_input_mode"
+	beetlePSXConfigGunInputModeValueLightgun = "lightgun"
+
+	beetlePSXConfigGunCursorKey        = "beetle_psx_gun_cursor"
+	beetlePSXConfigGunCursorValueCross = "cross"
+
+	beetlePSXConfigMultitapPort1Key     = "beetle_psx_enable_multitap_port1"
+	beetlePSXConfigMultitapPort2Key     = "beetle_psx_enable_multitap_port2"
+	beetlePSXConfigMultitapValueEnabled = "enabled"
 )
 
+// The input devices available in the Beetle PSX cores.
+var beetlePSXInputDevices = []data.InputDevice{
+	data.InputDeviceStandard,
+	data.InputDeviceAnalog,
+	data.InputDeviceDualShock,
+	data.InputDeviceMouse,
+	data.InputDeviceNeGcon,
+	data.InputDeviceGunCon,
+	data.InputDeviceJustifier,
+}
+
 // beetlePSX represents the Beetle PSX emulator core in RetroArch.
 //
 // See:
@@ -35,21 +55,40 @@ type beetlePSX struct {
 }
 
 // NewBeetlePSX returns a Configurator for the Beetle PSX core in RetroArch.
-func NewBeetlePSX() emuconf.Configurator {
+func NewBeetlePSX(profile emuconf.Profile) emuconf.Configurator {
 	return &beetlePSX{
 		core: &core{
 			internalName: beetlePSXInternalName,
 			displayName:  CoreNameBeetlePSX,
+			managedOptions: []string{
+				beetlePSXConfigAnalogToggleKey,
+				beetlePSXConfigGunInputModeKey,
+				beetlePSXConfigGunCursorKey,
+				beetlePSXConfigMultitapPort1Key,
+				beetlePSXConfigMultitapPort2Key,
+			},
+			profile: profile,
 		},
 	}
 }
 
 func (e *beetlePSX) Configure(writer io.Writer, app data.App) error {
-	var err error
+	return configure(e, writer, app)
+}
+
+func (e *beetlePSX) Options(app data.App) (emuconf.Options, error) {
+	var options emuconf.Options
 
 	var analogToggleValue string
 
+	app = e.profile.Resolve(app)
+
+	// The analog toggle only applies to controllers, not other input devices
+	device := selectInputDevice(app, e.profile, beetlePSXInputDevices)
+
 	switch {
+	case !device.IsController():
+		analogToggleValue = beetlePSXConfigAnalogToggleValueDisabled
 	case app.FeatureSupport.AnalogSupport == data.AnalogSupportYes,
 		app.FeatureSupport.AnalogSupport == data.AnalogSupportRequired:
 		analogToggleValue = beetlePSXConfigAnalogToggleValueEnabled
@@ -60,7 +99,22 @@ func (e *beetlePSX) Configure(writer io.Writer, app data.App) error {
 		analogToggleValue = beetlePSXConfigAnalogToggleValueDisabled
 	}
 
-	_, err = fmt.Fprintf(writer, "%s = %s\n", beetlePSXConfigAnalogToggleKey, analogToggleValue)
+	options.Set(beetlePSXConfigAnalogToggleKey, analogToggleValue)
+
+	if device.IsLightGun() {
+		// Aim with a light gun (rather than a touchscreen), with a crosshair
+		options.Set(beetlePSXConfigGunInputModeKey, beetlePSXConfigGunInputModeValueLightgun)
+		options.Set(beetlePSXConfigGunCursorKey, beetlePSXConfigGunCursorValueCross)
+	}
+
+	// Plug in a Multitap for the controllers beyond the ports of the console
+	multitap1, multitap2 := e.profile.Multitaps(app)
+	if multitap1 {
+		options.Set(beetlePSXConfigMultitapPort1Key, beetlePSXConfigMultitapValueEnabled)
+	}
+	if multitap2 {
+		options.Set(beetlePSXConfigMultitapPort2Key, beetlePSXConfigMultitapValueEnabled)
+	}
 
-	return err
+	return options, nil
 }
@@ -3,7 +3,6 @@
 package retroarch
 
 import (
-	"fmt"
 	"io"
 
 	"github.com/Rican7/psx-emu-conf/internal/data"
@@ -20,8 +19,18 @@ const (
 const (
 	beetlePSXHWConfigAnalogToggleKey = "beetle_psx_hw_analog_toggle"
 
-	beetlePSXHWConfigAnalogToggleValueDisabled = `"disabled"`
-	beetlePSXHWConfigAnalogToggleValueEnabled  = `"enabled"`
+	beetlePSXHWConfigAnalogToggleValueDisabled = "disabled"
+	beetlePSXHWConfigAnalogToggleValueEnabled  = "enabled"
+
+	beetlePSXHWConfigGunInputModeKey           = "beetle_psx_hw_gun_input_mode"
+	beetlePSXHWConfigGunInputModeValueLightgun = "lightgun"
+
+	beetlePSXHWConfigGunCursorKey        = "beetle_psx_hw_gun_cursor"
+	beetlePSXHWConfigGunCursorValueCross = "cross"
+
+	beetlePSXHWConfigMultitapPort1Key     = "beetle_psx_hw_enable_multitap_port1"
+	beetlePSXHWConfigMultitapPort2Key     = "beetle_psx_hw_enable_multitap_port2"
+	beetlePSXHWConfigMultitapValueEnabled = "enabled"
 )
 
 // beetlePSXHW represents the Beetle PSX HW emulator core in RetroArch.
@@ -35,21 +44,40 @@ type beetlePSXHW struct {
 }
 
 // NewBeetlePSXHW returns a Configurator for the Beetle PSX HW core in RetroArch.
-func NewBeetlePSXHW() emuconf.Configurator {
+func NewBeetlePSXHW(profile emuconf.Profile) emuconf.Configurator {
 	return &beetlePSXHW{
 		core: &core{
 			internalName: beetlePSXHWInternalName,
 			displayName:  CoreNameBeetlePSXHW,
+			managedOptions: []string{
+				beetlePSXHWConfigAnalogToggleKey,
+				beetlePSXHWConfigGunInputModeKey,
+				beetlePSXHWConfigGunCursorKey,
+				beetlePSXHWConfigMultitapPort1Key,
+				beetlePSXHWConfigMultitapPort2Key,
+			},
+			profile: profile,
 		},
 	}
 }
 
 func (e *beetlePSXHW) Configure(writer io.Writer, app data.App) error {
-	var err error
+	return configure(e, writer, app)
+}
+
+func (e *beetlePSXHW) Options(app data.App) (emuconf.Options, error) {
+	var options emuconf.Options
 
 	var analogToggleValue string
 
+	app = e.profile.Resolve(app)
+
+	// The analog toggle only applies to controllers, not other input devices
+	device := selectInputDevice(app, e.profile, beetlePSXInputDevices)
+
 	switch {
+	case !device.IsController():
+		analogToggleValue = beetlePSXHWConfigAnalogToggleValueDisabled
 	case app.FeatureSupport.AnalogSupport == data.AnalogSupportYes,
 		app.FeatureSupport.AnalogSupport == data.AnalogSupportRequired:
 		analogToggleValue = beetlePSXHWConfigAnalogToggleValueEnabled
@@ -60,7 +88,22 @@ func (e *beetlePSXHW) Configure(writer io.Writer, app data.App) error {
 		analogToggleValue = beetlePSXHWConfigAnalogToggleValueDisabled
 	}
 
-	_, err = fmt.Fprintf(writer, "%s = %s\n", beetlePSXHWConfigAnalogToggleKey, analogToggleValue)
+	options.Set(beetlePSXHWConfigAnalogToggleKey, analogToggleValue)
+
+	if device.IsLightGun() {
+		// Aim with a light gun (rather than a touchscreen), with a crosshair
+		options.Set(beetlePSXHWConfigGunInputModeKey, beetlePSXHWConfigGunInputModeValueLightgun)
+		options.Set(beetlePSXHWConfigGunCursorKey, beetlePSXHWConfigGunCursorValueCross)
+	}
+
+	// Plug in a Multitap for the controllers beyond the ports of the console
+	multitap1, multitap2 := e.profile.Multitaps(app)
+	if multitap1 {
+		options.Set(beetlePSXHWConfigMultitapPort1Key, beetlePSXHWConfigMultitapValueEnabled)
+	}
+	if multitap2 {
+		options.Set(beetlePSXHWConfigMultitapPort2Key, beetlePSXHWConfigMultitapValueEnabled)
+	}
 
-	return err
+	return options, nil
 }
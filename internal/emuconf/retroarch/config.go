@@ -0,0 +1,180 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package retroarch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Config defines a RetroArch configuration file, such as a core option file or
+// a configuration override file, in the `key = "value"` format.
+//
+// The comments, blank lines, and the ordering of the lines are preserved, so
+// that a file can be modified without losing anything set by hand.
+type Config struct {
+	lines []configLine
+}
+
+// configLine defines a single line of a RetroArch configuration file.
+type configLine struct {
+	raw string // The original text of the line.
+
+	// The key and (unquoted) value of the line, if it's an option.
+	key   string
+	value string
+}
+
+// isOption returns true if the line is an option, rather than a comment or a
+// blank line.
+func (l configLine) isOption() bool {
+	return l.key != ""
+}
+
+// ParseConfig reads a RetroArch configuration file from the given reader.
+func ParseConfig(reader io.Reader) (*Config, error) {
+	config := &Config{}
+
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := configLine{raw: scanner.Text()}
+
+		trimmed := strings.TrimSpace(line.raw)
+
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			parts := strings.SplitN(trimmed, "=", 2)
+			if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+				return nil, fmt.Errorf("invalid config line %d: %q", lineNumber, line.raw)
+			}
+
+			line.key = strings.TrimSpace(parts[0])
+			line.value = unquoteConfigValue(strings.TrimSpace(parts[1]))
+		}
+
+		config.lines = append(config.lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Get returns the value of the option of the given key, and whether the option
+// is set.
+//
+// If the option is set more than once, the last value is returned, as it's the
+// one that takes effect.
+func (c *Config) Get(key string) (string, bool) {
+	for i := len(c.lines) - 1; i >= 0; i-- {
+		if c.lines[i].key == key {
+			return c.lines[i].value, true
+		}
+	}
+
+	return "", false
+}
+
+// Set sets the value of the option of the given key, replacing the existing
+// option in place, or appending it if it isn't set.
+func (c *Config) Set(key, value string) {
+	found := false
+
+	for i, line := range c.lines {
+		if line.key != key {
+			continue
+		}
+
+		found = true
+
+		if line.value != value {
+			c.lines[i] = newConfigLine(key, value)
+		}
+	}
+
+	if !found {
+		c.lines = append(c.lines, newConfigLine(key, value))
+	}
+}
+
+// Delete removes every line that sets the option of the given key.
+func (c *Config) Delete(key string) {
+	lines := c.lines[:0]
+
+	for _, line := range c.lines {
+		if line.key != key {
+			lines = append(lines, line)
+		}
+	}
+
+	c.lines = lines
+}
+
+// Keys returns the keys of the options that are set, in order.
+func (c *Config) Keys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+
+	for _, line := range c.lines {
+		if line.isOption() && !seen[line.key] {
+			seen[line.key] = true
+			keys = append(keys, line.key)
+		}
+	}
+
+	return keys
+}
+
+// Merge sets each of the options of the given config, keeping every other line.
+func (c *Config) Merge(other *Config) {
+	for _, key := range other.Keys() {
+		value, _ := other.Get(key)
+
+		c.Set(key, value)
+	}
+}
+
+// WriteTo writes the config to the given writer.
+func (c *Config) WriteTo(writer io.Writer) (int64, error) {
+	var written int64
+
+	for _, line := range c.lines {
+		n, err := fmt.Fprintln(writer, line.raw)
+		written += int64(n)
+
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+func newConfigLine(key, value string) configLine {
+	return configLine{
+		raw:   fmt.Sprintf("%s = %s", key, quoteConfigValue(value)),
+		key:   key,
+		value: value,
+	}
+}
+
+// quoteConfigValue quotes a value for a config file.
+//
+// NOTE: RetroArch has no escape sequences in its config files, so the value is
+// simply wrapped in double quotes.
+func quoteConfigValue(value string) string {
+	return `"` + value + `"`
+}
+
+// unquoteConfigValue removes the quotes around a value of a config file, if it
+// has them.
+func unquoteConfigValue(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
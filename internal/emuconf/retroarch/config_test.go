@@ -0,0 +1,238 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package retroarch
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		wantKeys []string
+		want     map[string]string
+	}{
+		{
+			name:     "empty",
+			config:   "",
+			wantKeys: nil,
+			want:     map[string]string{},
+		},
+		{
+			name:     "quoted values",
+			config:   "a = \"1\"\nb = \"two words\"\n",
+			wantKeys: []string{"a", "b"},
+			want:     map[string]string{"a": "1", "b": "two words"},
+		},
+		{
+			name:     "unquoted values and loose spacing",
+			config:   "a=1\n  b   =   \"2\"  \nc = \"\"\n",
+			wantKeys: []string{"a", "b", "c"},
+			want:     map[string]string{"a": "1", "b": "2", "c": ""},
+		},
+		{
+			name:     "comments and blank lines",
+			config:   "# a comment\n\na = \"1\"\n  # indented = \"comment\"\n",
+			wantKeys: []string{"a"},
+			want:     map[string]string{"a": "1"},
+		},
+		{
+			name:     "repeated keys take the last value",
+			config:   "a = \"1\"\nb = \"2\"\na = \"3\"\n",
+			wantKeys: []string{"a", "b"},
+			want:     map[string]string{"a": "3", "b": "2"},
+		},
+		{
+			name:     "equals signs in values",
+			config:   "a = \"x=y\"\n",
+			wantKeys: []string{"a"},
+			want:     map[string]string{"a": "x=y"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config, err := ParseConfig(strings.NewReader(test.config))
+			if err != nil {
+				t.Fatalf("ParseConfig returned error: %v", err)
+			}
+
+			if got := config.Keys(); !equalStrings(got, test.wantKeys) {
+				t.Errorf("Keys() = %q, want %q", got, test.wantKeys)
+			}
+
+			for key, want := range test.want {
+				if got, ok := config.Get(key); !ok || got != want {
+					t.Errorf("Get(%q) = %q, %t, want %q, true", key, got, ok, want)
+				}
+			}
+
+			// Every line is written back as it was read
+			var written bytes.Buffer
+			if _, err := config.WriteTo(&written); err != nil {
+				t.Fatalf("WriteTo returned error: %v", err)
+			}
+
+			if written.String() != test.config {
+				t.Errorf("WriteTo wrote %q, want %q", written.String(), test.config)
+			}
+		})
+	}
+}
+
+func TestParseConfigInvalid(t *testing.T) {
+	tests := []string{
+		"a\n",
+		"a = \"1\"\nnot an option\n",
+		" = \"1\"\n",
+	}
+
+	for _, config := range tests {
+		if _, err := ParseConfig(strings.NewReader(config)); err == nil {
+			t.Errorf("ParseConfig(%q) returned no error", config)
+		}
+	}
+}
+
+func TestConfigMerge(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  string
+		generated string
+		want      string
+	}{
+		{
+			name:      "into empty",
+			existing:  "",
+			generated: "a = \"1\"\nb = \"2\"\n",
+			want:      "a = \"1\"\nb = \"2\"\n",
+		},
+		{
+			name:      "unchanged lines are kept as they are",
+			existing:  "a=\"1\"\n",
+			generated: "a = \"1\"\n",
+			want:      "a=\"1\"\n",
+		},
+		{
+			name:      "changed options are replaced in place",
+			existing:  "# header\nx = \"hand\"\na = \"old\"\n\ny = \"set\"\n",
+			generated: "a = \"new\"\n",
+			want:      "# header\nx = \"hand\"\na = \"new\"\n\ny = \"set\"\n",
+		},
+		{
+			name:      "new options are appended",
+			existing:  "x = \"hand\"\n# trailing comment\n",
+			generated: "a = \"1\"\n",
+			want:      "x = \"hand\"\n# trailing comment\na = \"1\"\n",
+		},
+		{
+			name:      "repeated options are all replaced",
+			existing:  "a = \"1\"\nx = \"hand\"\na = \"2\"\n",
+			generated: "a = \"3\"\n",
+			want:      "a = \"3\"\nx = \"hand\"\na = \"3\"\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			existing, err := ParseConfig(strings.NewReader(test.existing))
+			if err != nil {
+				t.Fatalf("ParseConfig returned error: %v", err)
+			}
+
+			generated, err := ParseConfig(strings.NewReader(test.generated))
+			if err != nil {
+				t.Fatalf("ParseConfig returned error: %v", err)
+			}
+
+			existing.Merge(generated)
+
+			var written bytes.Buffer
+			if _, err := existing.WriteTo(&written); err != nil {
+				t.Fatalf("WriteTo returned error: %v", err)
+			}
+
+			if written.String() != test.want {
+				t.Errorf("Merge wrote %q, want %q", written.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestCoreMergeAndPrune(t *testing.T) {
+	c := &core{managedOptions: []string{"managed_a", "managed_b"}}
+
+	tests := []struct {
+		name       string
+		existing   string
+		generated  string
+		wantMerged string
+		wantPruned string
+
+		// The merged config, when the stale managed options aren't pruned.
+		wantMergedKept string
+	}{
+		{
+			name:           "stale managed options",
+			existing:       "managed_a = \"1\"\nmine = \"x\"\nmanaged_b = \"2\"\n",
+			generated:      "managed_a = \"3\"\n",
+			wantMerged:     "managed_a = \"3\"\nmine = \"x\"\n",
+			wantPruned:     "mine = \"x\"\n",
+			wantMergedKept: "managed_a = \"3\"\nmine = \"x\"\nmanaged_b = \"2\"\n",
+		},
+		{
+			name:           "unmanaged options are kept",
+			existing:       "# comment\nother = \"1\"\n",
+			generated:      "managed_b = \"2\"\n",
+			wantMerged:     "# comment\nother = \"1\"\nmanaged_b = \"2\"\n",
+			wantPruned:     "# comment\nother = \"1\"\n",
+			wantMergedKept: "# comment\nother = \"1\"\nmanaged_b = \"2\"\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, prune := range []bool{true, false} {
+				want := test.wantMerged
+				if !prune {
+					want = test.wantMergedKept
+				}
+
+				var merged bytes.Buffer
+				if err := c.Merge(&merged, strings.NewReader(test.existing), strings.NewReader(test.generated), prune); err != nil {
+					t.Fatalf("Merge returned error: %v", err)
+				}
+
+				if merged.String() != want {
+					t.Errorf("Merge with prune %t wrote %q, want %q", prune, merged.String(), want)
+				}
+			}
+
+			var pruned bytes.Buffer
+			if err := c.Prune(&pruned, strings.NewReader(test.existing)); err != nil {
+				t.Fatalf("Prune returned error: %v", err)
+			}
+
+			if pruned.String() != test.wantPruned {
+				t.Errorf("Prune wrote %q, want %q", pruned.String(), test.wantPruned)
+			}
+		})
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
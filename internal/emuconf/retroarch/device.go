@@ -0,0 +1,28 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package retroarch
+
+import (
+	"github.com/Rican7/psx-emu-conf/internal/data"
+	"github.com/Rican7/psx-emu-conf/internal/emuconf"
+)
+
+// selectInputDevice selects the most appropriate input device for the given
+// app, given the preferences of the profile, from the input devices that are
+// available in a core.
+//
+// If none of the input devices supported by the app are available, the
+// standard controller is selected.
+func selectInputDevice(app data.App, profile emuconf.Profile, available []data.InputDevice) data.InputDevice {
+	app = profile.Resolve(app)
+
+	for _, device := range app.FeatureSupport.InputDevices(profile.InputDevices()...) {
+		for _, availableDevice := range available {
+			if availableDevice == device {
+				return device
+			}
+		}
+	}
+
+	return data.InputDeviceStandard
+}
@@ -0,0 +1,79 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package retroarch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// KeyConfigDirectory defines the key of the option in the main RetroArch
+	// configuration file (retroarch.cfg) that sets the directory where
+	// configurations are stored.
+	KeyConfigDirectory = "rgui_config_directory"
+
+	// valueDefaultDirectory defines the value that RetroArch writes for a
+	// directory option that isn't set, to use the default directory.
+	valueDefaultDirectory = "default"
+)
+
+// ConfigDirectory returns the directory where configurations are stored, as set
+// in the given main RetroArch configuration file (retroarch.cfg), which is in
+// the given directory.
+//
+// The directory is expanded with ExpandPath, with the given application
+// directory. If the directory isn't set, the default is returned, which is the
+// PathConfigDirectory within the directory of the main configuration file.
+func ConfigDirectory(mainConfig *Config, mainConfigDir string, applicationDir string) (string, error) {
+	configDir, ok := mainConfig.Get(KeyConfigDirectory)
+	if !ok || configDir == "" || configDir == valueDefaultDirectory {
+		return filepath.Join(mainConfigDir, PathConfigDirectory), nil
+	}
+
+	return ExpandPath(configDir, applicationDir)
+}
+
+// ExpandPath expands the special tokens that RetroArch allows at the start of
+// a path: "~" for the home directory of the user, and ":" for the application
+// directory of RetroArch.
+//
+// Relative paths are relative to the application directory, as RetroArch runs
+// from there.
+func ExpandPath(path string, applicationDir string) (string, error) {
+	switch {
+	case hasPathToken(path, "~"):
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+
+		path = filepath.Join(homeDir, trimPathToken(path))
+	case hasPathToken(path, ":"):
+		path = filepath.Join(applicationDir, trimPathToken(path))
+	case !filepath.IsAbs(path):
+		path = filepath.Join(applicationDir, path)
+	}
+
+	return filepath.Clean(path), nil
+}
+
+// hasPathToken returns true if the given path starts with the given token,
+// followed by a path separator or nothing at all.
+func hasPathToken(path string, token string) bool {
+	if !strings.HasPrefix(path, token) {
+		return false
+	}
+
+	rest := path[len(token):]
+
+	// Accept both separators, as RetroArch does on all platforms
+	return rest == "" || rest[0] == '/' || rest[0] == '\\'
+}
+
+// trimPathToken returns the given path without its leading token and the
+// separators that follow it.
+func trimPathToken(path string) string {
+	return strings.TrimLeft(path[1:], `/\`)
+}
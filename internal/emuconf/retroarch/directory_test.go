@@ -0,0 +1,84 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package retroarch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandPath(t *testing.T) {
+	homeDir := filepath.FromSlash("/home/player")
+	applicationDir := filepath.FromSlash("/opt/retroarch")
+
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", homeDir)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"~", homeDir},
+		{"~/config", filepath.Join(homeDir, "config")},
+		{`~\config`, filepath.Join(homeDir, "config")},
+		{"~//config/", filepath.Join(homeDir, "config")},
+		{":", applicationDir},
+		{":/config", filepath.Join(applicationDir, "config")},
+		{`:\config`, filepath.Join(applicationDir, "config")},
+		{"config", filepath.Join(applicationDir, "config")},
+		{"./config/../other", filepath.Join(applicationDir, "other")},
+		{"~player/config", filepath.Join(applicationDir, "~player", "config")},
+		{":config", filepath.Join(applicationDir, ":config")},
+		{"/etc/retroarch/config", filepath.FromSlash("/etc/retroarch/config")},
+		{"/etc/retroarch/../config/", filepath.FromSlash("/etc/config")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			got, err := ExpandPath(test.path, applicationDir)
+			if err != nil {
+				t.Fatalf("ExpandPath(%q) returned error: %v", test.path, err)
+			}
+
+			if got != test.want {
+				t.Errorf("ExpandPath(%q) = %q, want %q", test.path, got, test.want)
+			}
+		})
+	}
+}
+
+func TestConfigDirectory(t *testing.T) {
+	mainConfigDir := filepath.FromSlash("/home/player/.config/retroarch")
+	applicationDir := filepath.FromSlash("/opt/retroarch")
+
+	tests := []struct {
+		name       string
+		mainConfig string
+		want       string
+	}{
+		{"unset", "video_driver = \"gl\"\n", filepath.Join(mainConfigDir, PathConfigDirectory)},
+		{"empty", KeyConfigDirectory + " = \"\"\n", filepath.Join(mainConfigDir, PathConfigDirectory)},
+		{"default", KeyConfigDirectory + " = \"default\"\n", filepath.Join(mainConfigDir, PathConfigDirectory)},
+		{"set", KeyConfigDirectory + " = \":/configs\"\n", filepath.Join(applicationDir, "configs")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mainConfig, err := ParseConfig(strings.NewReader(test.mainConfig))
+			if err != nil {
+				t.Fatalf("ParseConfig returned error: %v", err)
+			}
+
+			got, err := ConfigDirectory(mainConfig, mainConfigDir, applicationDir)
+			if err != nil {
+				t.Fatalf("ConfigDirectory returned error: %v", err)
+			}
+
+			if got != test.want {
+				t.Errorf("ConfigDirectory = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package retroarch
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Rican7/psx-emu-conf/internal/data"
+	"github.com/Rican7/psx-emu-conf/internal/emuconf"
+	"github.com/Rican7/psx-emu-conf/internal/emuconf/encoding"
+)
+
+// The prefixes of the core option keys of each core, keyed by the internal name
+// of the core.
+var coreOptionPrefixes = map[string]string{
+	pcsxReARMedInternalName: "pcsx_rearmed_",
+	beetlePSXInternalName:   "beetle_psx_",
+	beetlePSXHWInternalName: "beetle_psx_hw_",
+}
+
+// OwnsOption returns true if the core option of the given key belongs to the
+// core.
+//
+// As the option prefixes of some cores are prefixes of others (ex: Beetle PSX
+// and Beetle PSX HW), an option belongs to the core with the longest matching
+// prefix.
+func (c *core) OwnsOption(key string) bool {
+	var owner string
+	var ownerPrefix string
+
+	for coreName, prefix := range coreOptionPrefixes {
+		if strings.HasPrefix(key, prefix) && len(prefix) > len(ownerPrefix) {
+			owner, ownerPrefix = coreName, prefix
+		}
+	}
+
+	return owner != "" && owner == c.internalName
+}
+
+// configure writes the options of the given configurator for the given app, in
+// the RetroArch config format.
+func configure(configurator emuconf.OptionsConfigurator, writer io.Writer, app data.App) error {
+	options, err := configurator.Options(app)
+	if err != nil {
+		return err
+	}
+
+	return encoding.RetroArch.Encode(writer, options)
+}
+
+// configureWithOptions writes the options of the given configurator for the
+// given app, in the RetroArch config format, with the values of the options
+// replaced by those of the given raw options, and the rest of the given raw
+// options appended in sorted order.
+func configureWithOptions(configurator emuconf.OptionsConfigurator, writer io.Writer, app data.App, rawOptions map[string]string) error {
+	options, err := configurator.Options(app)
+	if err != nil {
+		return err
+	}
+
+	var keys []string
+	for key := range rawOptions {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		options.Set(key, rawOptions[key])
+	}
+
+	return encoding.RetroArch.Encode(writer, options)
+}
+
+// Merge writes the given generated config, merged into the given existing
+// config, keeping the unknown options, comments, and ordering of the existing
+// config.
+//
+// If prune is true, the options managed by the core that aren't in the
+// generated config are removed, as they no longer apply.
+func (c *core) Merge(writer io.Writer, existing io.Reader, generated io.Reader, prune bool) error {
+	existingConfig, err := ParseConfig(existing)
+	if err != nil {
+		return fmt.Errorf("parsing existing config: %w", err)
+	}
+
+	generatedConfig, err := ParseConfig(generated)
+	if err != nil {
+		return err
+	}
+
+	existingConfig.Merge(generatedConfig)
+
+	if prune {
+		for _, key := range c.managedOptions {
+			if _, ok := generatedConfig.Get(key); !ok {
+				existingConfig.Delete(key)
+			}
+		}
+	}
+
+	_, err = existingConfig.WriteTo(writer)
+
+	return err
+}
+
+// Prune writes the given existing config, without the options managed by the
+// core, keeping everything else.
+func (c *core) Prune(writer io.Writer, existing io.Reader) error {
+	existingConfig, err := ParseConfig(existing)
+	if err != nil {
+		return fmt.Errorf("parsing existing config: %w", err)
+	}
+
+	for _, key := range c.managedOptions {
+		existingConfig.Delete(key)
+	}
+
+	_, err = existingConfig.WriteTo(writer)
+
+	return err
+}
+
+// portOptionKeys returns the keys of an option set for each of the maximum
+// number of controller ports, from the given key format.
+func portOptionKeys(keyFormat string) []string {
+	var keys []string
+
+	for i := 1; i <= emuconf.MaxPorts; i++ {
+		keys = append(keys, fmt.Sprintf(keyFormat, i))
+	}
+
+	return keys
+}
+
+func (e *pcsxReARMed) ConfigureWithOptions(writer io.Writer, app data.App, options map[string]string) error {
+	return configureWithOptions(e, writer, app, options)
+}
+
+func (e *beetlePSX) ConfigureWithOptions(writer io.Writer, app data.App, options map[string]string) error {
+	return configureWithOptions(e, writer, app, options)
+}
+
+func (e *beetlePSXHW) ConfigureWithOptions(writer io.Writer, app data.App, options map[string]string) error {
+	return configureWithOptions(e, writer, app, options)
+}
@@ -0,0 +1,136 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package retroarch
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/Rican7/psx-emu-conf/internal/data"
+	"github.com/Rican7/psx-emu-conf/internal/emuconf"
+)
+
+const (
+	overrideConfigInputDeviceKeyFormat = "input_libretro_device_p%d"
+)
+
+// The libretro device type IDs of the input devices of the Beetle PSX cores.
+//
+// See:
+//  - https://github.com/libretro/beetle-psx-libretro/blob/master/input.h
+var beetlePSXDeviceTypeIDs = map[data.InputDevice]uint{
+	data.InputDeviceStandard:  1,
+	data.InputDeviceAnalog:    517,
+	data.InputDeviceDualShock: 261,
+	data.InputDeviceMouse:     258,
+	data.InputDeviceNeGcon:    1029,
+	data.InputDeviceGunCon:    260,
+	data.InputDeviceJustifier: 516,
+}
+
+// The libretro device type IDs of the input devices of the PCSX ReARMed core.
+//
+// See:
+//  - https://github.com/libretro/pcsx_rearmed/blob/master/frontend/libretro.c
+var pcsxReARMedDeviceTypeIDs = map[data.InputDevice]uint{
+	data.InputDeviceStandard:  257,
+	data.InputDeviceAnalog:    261,
+	data.InputDeviceDualShock: 517,
+	data.InputDeviceMouse:     258,
+	data.InputDeviceNeGcon:    773,
+	data.InputDeviceGunCon:    260,
+}
+
+// deviceOverride represents a per-game configuration override of a core in
+// RetroArch, that sets the device types of the controller ports for apps that
+// are played with an input device other than a controller, such as a light
+// gun.
+//
+// As the device types of the controller ports aren't core options, they can't
+// be set in the per-game core option files.
+type deviceOverride struct {
+	*core
+
+	devices   []data.InputDevice
+	deviceIDs map[data.InputDevice]uint
+}
+
+// NewPCSXReARMedDeviceOverride returns a Configurator for the device types of
+// the PCSX ReARMed core in RetroArch.
+func NewPCSXReARMedDeviceOverride(profile emuconf.Profile) emuconf.Configurator {
+	return &deviceOverride{
+		core: &core{
+			internalName:   pcsxReARMedInternalName,
+			displayName:    CoreNamePCSXReARMed,
+			managedOptions: portOptionKeys(overrideConfigInputDeviceKeyFormat),
+			profile:        profile,
+		},
+		devices:   pcsxReARMedInputDevices,
+		deviceIDs: pcsxReARMedDeviceTypeIDs,
+	}
+}
+
+// NewBeetlePSXDeviceOverride returns a Configurator for the device types of the
+// Beetle PSX core in RetroArch.
+func NewBeetlePSXDeviceOverride(profile emuconf.Profile) emuconf.Configurator {
+	return &deviceOverride{
+		core: &core{
+			internalName:   beetlePSXInternalName,
+			displayName:    CoreNameBeetlePSX,
+			managedOptions: portOptionKeys(overrideConfigInputDeviceKeyFormat),
+			profile:        profile,
+		},
+		devices:   beetlePSXInputDevices,
+		deviceIDs: beetlePSXDeviceTypeIDs,
+	}
+}
+
+// NewBeetlePSXHWDeviceOverride returns a Configurator for the device types of
+// the Beetle PSX HW core in RetroArch.
+func NewBeetlePSXHWDeviceOverride(profile emuconf.Profile) emuconf.Configurator {
+	return &deviceOverride{
+		core: &core{
+			internalName:   beetlePSXHWInternalName,
+			displayName:    CoreNameBeetlePSXHW,
+			managedOptions: portOptionKeys(overrideConfigInputDeviceKeyFormat),
+			profile:        profile,
+		},
+		devices:   beetlePSXInputDevices,
+		deviceIDs: beetlePSXDeviceTypeIDs,
+	}
+}
+
+func (o *deviceOverride) EmulatorName() string {
+	return fmt.Sprintf("%s (device override)", o.core.EmulatorName())
+}
+
+func (o *deviceOverride) Path(app data.App) string {
+	return pathForGameFile(o.internalName, app, ExtensionPerGameOverride)
+}
+
+func (o *deviceOverride) AlternativePaths(app data.App) []string {
+	return altPathsForGameFile(o.internalName, app, ExtensionPerGameOverride)
+}
+
+// Accepts returns true if the app is played with an input device other than a
+// controller, as the device types of controllers are left to the user.
+func (o *deviceOverride) Accepts(app data.App) bool {
+	return !selectInputDevice(app, o.profile, o.devices).IsController()
+}
+
+func (o *deviceOverride) Configure(writer io.Writer, app data.App) error {
+	return configure(o, writer, app)
+}
+
+func (o *deviceOverride) Options(app data.App) (emuconf.Options, error) {
+	var options emuconf.Options
+
+	deviceID := o.deviceIDs[selectInputDevice(app, o.profile, o.devices)]
+
+	for i := 1; i <= o.profile.AppPorts(app); i++ {
+		options.Set(fmt.Sprintf(overrideConfigInputDeviceKeyFormat, i), strconv.FormatUint(uint64(deviceID), 10))
+	}
+
+	return options, nil
+}
@@ -20,11 +20,57 @@ const (
 const (
 	pcsxReARMedConfigControllerTypeKeyFormat = "pcsx_rearmed_pad%dtype"
 
-	pcsxReARMedConfigControllerTypeValueStandard  = `"standard"`
-	pcsxReARMedConfigControllerTypeValueAnalog    = `"analog"`
-	pcsxReARMedConfigControllerTypeValueDualShock = `"dualshock"`
+	pcsxReARMedConfigControllerTypeValueStandard  = "standard"
+	pcsxReARMedConfigControllerTypeValueAnalog    = "analog"
+	pcsxReARMedConfigControllerTypeValueDualShock = "dualshock"
+	pcsxReARMedConfigControllerTypeValueMouse     = "mouse"
+	pcsxReARMedConfigControllerTypeValueNeGcon    = "negcon"
+	pcsxReARMedConfigControllerTypeValueGunCon    = "guncon"
+
+	pcsxReARMedConfigGunConAdjustXKey      = "pcsx_rearmed_gunconadjustx"
+	pcsxReARMedConfigGunConAdjustYKey      = "pcsx_rearmed_gunconadjusty"
+	pcsxReARMedConfigGunConAdjustRatioXKey = "pcsx_rearmed_gunconadjustratiox"
+	pcsxReARMedConfigGunConAdjustRatioYKey = "pcsx_rearmed_gunconadjustratioy"
+
+	pcsxReARMedConfigGunConAdjustValueNone      = "0"
+	pcsxReARMedConfigGunConAdjustRatioValueNone = "1.00"
+
+	pcsxReARMedConfigCrosshairKeyFormat = "pcsx_rearmed_crosshair%d"
+
+	pcsxReARMedConfigMultitapKey             = "pcsx_rearmed_multitap"
+	pcsxReARMedConfigMultitapValuePort1      = "port 1"
+	pcsxReARMedConfigMultitapValuePorts1And2 = "ports 1 and 2"
+
+	pcsxReARMedConfigVibrationKey           = "pcsx_rearmed_vibration"
+	pcsxReARMedConfigVibrationValueDisabled = "disabled"
+	pcsxReARMedConfigVibrationValueEnabled  = "enabled"
 )
 
+// The crosshair colors of each player, so that each player can be told apart.
+var pcsxReARMedConfigCrosshairValues = []string{"blue", "red"}
+
+// The input devices available in the PCSX ReARMed core.
+//
+// NOTE: The Konami Justifier isn't emulated by PCSX ReARMed.
+var pcsxReARMedInputDevices = []data.InputDevice{
+	data.InputDeviceStandard,
+	data.InputDeviceAnalog,
+	data.InputDeviceDualShock,
+	data.InputDeviceMouse,
+	data.InputDeviceNeGcon,
+	data.InputDeviceGunCon,
+}
+
+// The controller type values of the input devices of the PCSX ReARMed core.
+var pcsxReARMedControllerTypeValues = map[data.InputDevice]string{
+	data.InputDeviceStandard:  pcsxReARMedConfigControllerTypeValueStandard,
+	data.InputDeviceAnalog:    pcsxReARMedConfigControllerTypeValueAnalog,
+	data.InputDeviceDualShock: pcsxReARMedConfigControllerTypeValueDualShock,
+	data.InputDeviceMouse:     pcsxReARMedConfigControllerTypeValueMouse,
+	data.InputDeviceNeGcon:    pcsxReARMedConfigControllerTypeValueNeGcon,
+	data.InputDeviceGunCon:    pcsxReARMedConfigControllerTypeValueGunCon,
+}
+
 // pcsxReARMed emulator core in RetroArch.
 //
 // See:
@@ -36,42 +82,84 @@ type pcsxReARMed struct {
 }
 
 // NewPCSXReARMed returns a Configurator for the PCSX ReARMed core in RetroArch.
-func NewPCSXReARMed() emuconf.Configurator {
+func NewPCSXReARMed(profile emuconf.Profile) emuconf.Configurator {
 	return &pcsxReARMed{
 		core: &core{
-			internalName: pcsxReARMedInternalName,
-			displayName:  CoreNamePCSXReARMed,
+			internalName:   pcsxReARMedInternalName,
+			displayName:    CoreNamePCSXReARMed,
+			managedOptions: pcsxReARMedManagedOptions(),
+			profile:        profile,
 		},
 	}
 }
 
+// pcsxReARMedManagedOptions returns the keys of the options that the PCSX
+// ReARMed core sets for some apps.
+func pcsxReARMedManagedOptions() []string {
+	keys := portOptionKeys(pcsxReARMedConfigControllerTypeKeyFormat)
+
+	keys = append(
+		keys,
+		pcsxReARMedConfigMultitapKey,
+		pcsxReARMedConfigVibrationKey,
+		pcsxReARMedConfigGunConAdjustXKey,
+		pcsxReARMedConfigGunConAdjustYKey,
+		pcsxReARMedConfigGunConAdjustRatioXKey,
+		pcsxReARMedConfigGunConAdjustRatioYKey,
+	)
+
+	for i := range pcsxReARMedConfigCrosshairValues {
+		keys = append(keys, fmt.Sprintf(pcsxReARMedConfigCrosshairKeyFormat, i+1))
+	}
+
+	return keys
+}
+
 func (e *pcsxReARMed) Configure(writer io.Writer, app data.App) error {
-	var err error
-
-	var controllerTypeValue string
-
-	switch {
-	case app.FeatureSupport.RumbleSupport == data.RumbleSupportYes:
-		controllerTypeValue = pcsxReARMedConfigControllerTypeValueDualShock
-	case app.FeatureSupport.AnalogSupport == data.AnalogSupportYes,
-		app.FeatureSupport.AnalogSupport == data.AnalogSupportRequired:
-		controllerTypeValue = pcsxReARMedConfigControllerTypeValueAnalog
-	case app.FeatureSupport.AnalogSupport == data.AnalogSupportNo,
-		app.FeatureSupport.AnalogSupport == data.AnalogSupportUnknown:
-		fallthrough
-	default:
-		controllerTypeValue = pcsxReARMedConfigControllerTypeValueStandard
+	return configure(e, writer, app)
+}
+
+func (e *pcsxReARMed) Options(app data.App) (emuconf.Options, error) {
+	var options emuconf.Options
+
+	device := selectInputDevice(app, e.profile, pcsxReARMedInputDevices)
+	controllerTypeValue := pcsxReARMedControllerTypeValues[device]
+
+	ports := e.profile.AppPorts(app)
+
+	// Set a value for each controller.
+	for i := 1; i <= ports; i++ {
+		options.Set(fmt.Sprintf(pcsxReARMedConfigControllerTypeKeyFormat, i), controllerTypeValue)
+	}
+
+	// Plug in a Multitap for the controllers beyond the ports of the console
+	switch multitap1, multitap2 := e.profile.Multitaps(app); {
+	case multitap2:
+		options.Set(pcsxReARMedConfigMultitapKey, pcsxReARMedConfigMultitapValuePorts1And2)
+	case multitap1:
+		options.Set(pcsxReARMedConfigMultitapKey, pcsxReARMedConfigMultitapValuePort1)
+	}
+
+	if enabled, ok := e.profile.RumbleEnabled(); ok {
+		vibrationValue := pcsxReARMedConfigVibrationValueDisabled
+		if enabled {
+			vibrationValue = pcsxReARMedConfigVibrationValueEnabled
+		}
+
+		options.Set(pcsxReARMedConfigVibrationKey, vibrationValue)
 	}
 
-	// Write a value for each controller.
-	//
-	// TODO: This currently writes configs for two controllers. Support more
-	// than that? How many? A configurable amount?
-	for i := 1; i <= 2; i++ {
-		key := fmt.Sprintf(pcsxReARMedConfigControllerTypeKeyFormat, i)
+	if device == data.InputDeviceGunCon {
+		// Reset the calibration, and show a crosshair for each player
+		options.Set(pcsxReARMedConfigGunConAdjustXKey, pcsxReARMedConfigGunConAdjustValueNone)
+		options.Set(pcsxReARMedConfigGunConAdjustYKey, pcsxReARMedConfigGunConAdjustValueNone)
+		options.Set(pcsxReARMedConfigGunConAdjustRatioXKey, pcsxReARMedConfigGunConAdjustRatioValueNone)
+		options.Set(pcsxReARMedConfigGunConAdjustRatioYKey, pcsxReARMedConfigGunConAdjustRatioValueNone)
 
-		_, err = fmt.Fprintf(writer, "%s = %s\n", key, controllerTypeValue)
+		for i := 1; i <= ports && i <= len(pcsxReARMedConfigCrosshairValues); i++ {
+			options.Set(fmt.Sprintf(pcsxReARMedConfigCrosshairKeyFormat, i), pcsxReARMedConfigCrosshairValues[i-1])
+		}
 	}
 
-	return err
+	return options, nil
 }
@@ -17,6 +17,7 @@ import (
 	"path"
 
 	"github.com/Rican7/psx-emu-conf/internal/data"
+	"github.com/Rican7/psx-emu-conf/internal/emuconf"
 )
 
 const (
@@ -29,17 +30,27 @@ const (
 	// NOTE: This is the DEFAULT value, and is relative to the path where
 	// RetroArch stores its default directories. As RetroArch allows for the
 	// specification of directory paths, this could differ per user,
-	// installation, or configuration.
+	// installation, or configuration. See ConfigDirectory.
 	PathConfigDirectory = "config"
 
 	// ExtensionPerGameCoreOption defines the file extension used for per-game
 	// core option files.
 	ExtensionPerGameCoreOption = ".opt"
+
+	// ExtensionPerGameOverride defines the file extension used for per-game
+	// configuration override files.
+	ExtensionPerGameOverride = ".cfg"
 )
 
 type core struct {
 	internalName string
 	displayName  string
+
+	// The keys of the options that the core sets for some apps, so that they
+	// can be removed from the apps that they no longer apply to.
+	managedOptions []string
+
+	profile emuconf.Profile
 }
 
 func (c *core) EmulatorName() string {
@@ -47,22 +58,22 @@ func (c *core) EmulatorName() string {
 }
 
 func (c *core) Path(app data.App) string {
-	return pathForGameCoreOptionFile(c.internalName, app)
+	return pathForGameFile(c.internalName, app, ExtensionPerGameCoreOption)
 }
 
 func (c *core) AlternativePaths(app data.App) []string {
-	return altPathsForGameCoreOptionFile(c.internalName, app)
+	return altPathsForGameFile(c.internalName, app, ExtensionPerGameCoreOption)
 }
 
-func pathForGameCoreOptionFile(coreName string, app data.App) string {
-	return path.Join(coreName, app.Title+ExtensionPerGameCoreOption)
+func pathForGameFile(coreName string, app data.App, extension string) string {
+	return path.Join(coreName, app.Title+extension)
 }
 
-func altPathsForGameCoreOptionFile(coreName string, app data.App) []string {
+func altPathsForGameFile(coreName string, app data.App, extension string) []string {
 	var altPaths []string
 
 	for _, titleVariation := range app.TitleVariations {
-		altPath := path.Join(coreName, titleVariation+ExtensionPerGameCoreOption)
+		altPath := path.Join(coreName, titleVariation+extension)
 
 		altPaths = append(altPaths, altPath)
 	}
@@ -0,0 +1,355 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+// Package install provides the tracking of the config files that are installed
+// into the directory of an emulator, with backups of the files that they
+// replace, so that they can be uninstalled.
+package install
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/Rican7/psx-emu-conf/internal/atomicfile"
+)
+
+const (
+	// DirName defines the name of the directory, within an install directory,
+	// where the manifest and the backups are stored.
+	DirName = ".psxemuconf"
+
+	manifestFileName = "manifest.json"
+	journalFileName  = "journal.jsonl"
+	backupDirName    = "backup"
+)
+
+// File defines a config file that was installed.
+type File struct {
+	// The path of the file, relative to the install directory, with forward
+	// slashes as separators.
+	Path string
+
+	// The SHA-256 hash of the content that was written, in hex.
+	SHA256 string
+
+	// Whether a file existed at the path before the first install, and was
+	// backed up to be restored on uninstall.
+	Backup bool `json:",omitempty"`
+}
+
+// Manifest defines a record of the config files that were installed into a
+// directory.
+//
+// Each change to the manifest is appended to a journal before the file that it
+// records is written, so that nothing is lost if an install is interrupted
+// before the manifest is saved. The journal is replayed when the manifest is
+// opened again.
+//
+// A Manifest is safe for concurrent use.
+type Manifest struct {
+	root    string
+	mutex   sync.Mutex
+	files   map[string]File
+	journal *os.File
+}
+
+// manifestFile defines the JSON form of a Manifest.
+type manifestFile struct {
+	Files []File
+}
+
+// journalEntry defines an entry of the journal of a Manifest: either a file
+// that's recorded, or the path of a file that's forgotten.
+type journalEntry struct {
+	File   *File  `json:",omitempty"`
+	Forget string `json:",omitempty"`
+}
+
+// OpenManifest loads the manifest of the given install directory, with any
+// changes from an interrupted install, or returns an empty manifest if there
+// isn't one yet.
+func OpenManifest(root string) (*Manifest, error) {
+	manifest := &Manifest{
+		root:  root,
+		files: make(map[string]File),
+	}
+
+	content, err := ioutil.ReadFile(manifest.manifestPath())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		var raw manifestFile
+		if err := json.Unmarshal(content, &raw); err != nil {
+			return nil, fmt.Errorf("reading manifest %s: %w", manifest.manifestPath(), err)
+		}
+
+		for _, file := range raw.Files {
+			manifest.files[file.Path] = file
+		}
+	}
+
+	if err := manifest.replayJournal(); err != nil {
+		return nil, fmt.Errorf("reading journal %s: %w", manifest.journalPath(), err)
+	}
+
+	return manifest, nil
+}
+
+// replayJournal applies the changes of the journal, if there is one.
+func (m *Manifest) replayJournal() error {
+	file, err := os.Open(m.journalPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry journalEntry
+
+		// An entry can only be incomplete if it was the last one written
+		// before an interruption, so there's nothing after it
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			break
+		}
+
+		switch {
+		case entry.File != nil:
+			m.files[entry.File.Path] = *entry.File
+		case entry.Forget != "":
+			delete(m.files, entry.Forget)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// Root returns the install directory of the manifest.
+func (m *Manifest) Root() string {
+	return m.root
+}
+
+// Files returns the installed files, in order of their paths.
+func (m *Manifest) Files() []File {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.sortedFiles()
+}
+
+func (m *Manifest) sortedFiles() []File {
+	files := make([]File, 0, len(m.files))
+
+	for _, file := range m.files {
+		files = append(files, file)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	return files
+}
+
+// Tracked returns true if the file at the given path, relative to the install
+// directory, was installed.
+func (m *Manifest) Tracked(path string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	_, ok := m.files[filepath.ToSlash(path)]
+
+	return ok
+}
+
+// Modified returns true if the file at the given path, relative to the install
+// directory, was installed and has been modified since, given its current
+// content.
+func (m *Manifest) Modified(path string, current []byte) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	file, ok := m.files[filepath.ToSlash(path)]
+
+	return ok && file.SHA256 != hash(current)
+}
+
+// Begin records that the given content is about to be written to the file at
+// the given path, relative to the install directory, backing up the given
+// original content of the file if it exists and wasn't already installed.
+//
+// The returned function reverts the record, and must be called if the write
+// fails.
+func (m *Manifest) Begin(path string, original []byte, exists bool, content []byte) (abort func() error, err error) {
+	key := filepath.ToSlash(path)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	previous, tracked := m.files[key]
+	file := previous
+
+	// Only the original files are backed up, and never an installed file
+	if !tracked && exists {
+		backupPath := m.backupPath(key)
+
+		if err := os.MkdirAll(filepath.Dir(backupPath), 0777); err != nil {
+			return nil, err
+		}
+
+		if err := atomicfile.WriteFile(backupPath, original, 0644); err != nil {
+			return nil, fmt.Errorf("backing up %s: %w", path, err)
+		}
+
+		file.Backup = true
+	}
+
+	file.Path = key
+	file.SHA256 = hash(content)
+
+	if err := m.appendJournal(journalEntry{File: &file}); err != nil {
+		return nil, err
+	}
+
+	m.files[key] = file
+
+	abort = func() error {
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+
+		if tracked {
+			m.files[key] = previous
+
+			return m.appendJournal(journalEntry{File: &previous})
+		}
+
+		delete(m.files, key)
+
+		if err := m.appendJournal(journalEntry{Forget: key}); err != nil {
+			return err
+		}
+
+		if err := os.Remove(m.backupPath(key)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		return nil
+	}
+
+	return abort, nil
+}
+
+// forget removes the installed file of the given key from the manifest.
+func (m *Manifest) forget(key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.files, key)
+
+	return m.appendJournal(journalEntry{Forget: key})
+}
+
+// appendJournal appends the given entry to the journal, opening it if needed.
+//
+// NOTE: The mutex must be held by the caller.
+func (m *Manifest) appendJournal(entry journalEntry) error {
+	if m.journal == nil {
+		if err := os.MkdirAll(filepath.Dir(m.journalPath()), 0777); err != nil {
+			return err
+		}
+
+		journal, err := os.OpenFile(m.journalPath(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return err
+		}
+
+		m.journal = journal
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// Write the line at once, so that only the last line can be incomplete
+	_, err = m.journal.Write(append(line, '\n'))
+
+	return err
+}
+
+// Save writes the manifest into the install directory, and clears the journal.
+func (m *Manifest) Save() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.journal != nil {
+		if err := m.journal.Close(); err != nil {
+			return err
+		}
+
+		m.journal = nil
+	}
+
+	if len(m.files) == 0 {
+		if err := os.Remove(m.manifestPath()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else {
+		content, err := json.MarshalIndent(manifestFile{Files: m.sortedFiles()}, "", "\t")
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(m.manifestPath()), 0777); err != nil {
+			return err
+		}
+
+		if err := atomicfile.WriteFile(m.manifestPath(), append(content, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+
+	// Only clear the journal once the manifest has its changes
+	if err := os.Remove(m.journalPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// Prune the directories left empty by restored or discarded backups
+	return removeEmptyTree(filepath.Join(m.root, DirName))
+}
+
+func (m *Manifest) manifestPath() string {
+	return filepath.Join(m.root, DirName, manifestFileName)
+}
+
+func (m *Manifest) journalPath() string {
+	return filepath.Join(m.root, DirName, journalFileName)
+}
+
+func (m *Manifest) filePath(key string) string {
+	return filepath.Join(m.root, filepath.FromSlash(key))
+}
+
+func (m *Manifest) backupPath(key string) string {
+	return filepath.Join(m.root, DirName, backupDirName, filepath.FromSlash(key))
+}
+
+// hash returns the SHA-256 hash of the given content, in hex.
+func hash(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
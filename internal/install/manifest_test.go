@@ -0,0 +1,242 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package install
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifestJournalReplay(t *testing.T) {
+	root := tempDir(t)
+	defer os.RemoveAll(root)
+
+	manifest := openManifest(t, root)
+
+	install(t, manifest, "a.cfg", "a")
+	install(t, manifest, "sub/b.cfg", "b")
+	install(t, manifest, "c.cfg", "c")
+
+	if err := manifest.forget("c.cfg"); err != nil {
+		t.Fatalf("forget returned error: %v", err)
+	}
+
+	// Interrupt the install, without saving, in the middle of an entry
+	manifest.journal.Write([]byte(`{"File":{"Path":"d.cfg"`))
+	manifest.journal.Close()
+
+	replayed := openManifest(t, root)
+
+	for path, want := range map[string]bool{"a.cfg": true, "sub/b.cfg": true, "c.cfg": false, "d.cfg": false} {
+		if got := replayed.Tracked(path); got != want {
+			t.Errorf("Tracked(%q) = %t, want %t", path, got, want)
+		}
+	}
+
+	if replayed.Modified("a.cfg", []byte("a")) {
+		t.Errorf("Modified(%q) = true, want false", "a.cfg")
+	}
+
+	if !replayed.Modified("a.cfg", []byte("changed")) {
+		t.Errorf("Modified(%q) = false, want true", "a.cfg")
+	}
+
+	// Saving clears the journal, and keeps the changes
+	if err := replayed.Save(); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	if _, err := os.Stat(replayed.journalPath()); !os.IsNotExist(err) {
+		t.Errorf("journal exists after Save, with error %v", err)
+	}
+
+	if files := openManifest(t, root).Files(); len(files) != 2 || files[0].Path != "a.cfg" || files[1].Path != "sub/b.cfg" {
+		t.Errorf("Files() = %+v, want a.cfg and sub/b.cfg", files)
+	}
+}
+
+func TestManifestBeginAbort(t *testing.T) {
+	root := tempDir(t)
+	defer os.RemoveAll(root)
+
+	manifest := openManifest(t, root)
+
+	// A new file, replacing an original file
+	writeFile(t, root, "new.cfg", "original")
+
+	abort, err := manifest.Begin("new.cfg", []byte("original"), true, []byte("new"))
+	if err != nil {
+		t.Fatalf("Begin returned error: %v", err)
+	}
+
+	assertFile(t, manifest.backupPath("new.cfg"), "original")
+
+	if err := abort(); err != nil {
+		t.Fatalf("abort returned error: %v", err)
+	}
+
+	if manifest.Tracked("new.cfg") {
+		t.Errorf("Tracked(%q) = true after abort, want false", "new.cfg")
+	}
+
+	if _, err := os.Stat(manifest.backupPath("new.cfg")); !os.IsNotExist(err) {
+		t.Errorf("backup exists after abort, with error %v", err)
+	}
+
+	// An installed file, which keeps its record
+	install(t, manifest, "installed.cfg", "first")
+
+	abort, err = manifest.Begin("installed.cfg", []byte("first"), true, []byte("second"))
+	if err != nil {
+		t.Fatalf("Begin returned error: %v", err)
+	}
+
+	if err := abort(); err != nil {
+		t.Fatalf("abort returned error: %v", err)
+	}
+
+	if manifest.Modified("installed.cfg", []byte("first")) {
+		t.Errorf("Modified(%q) = true after abort, want false", "installed.cfg")
+	}
+
+	// The abort is journaled too
+	manifest.journal.Close()
+
+	replayed := openManifest(t, root)
+
+	if replayed.Tracked("new.cfg") {
+		t.Errorf("Tracked(%q) = true after replay, want false", "new.cfg")
+	}
+
+	if replayed.Modified("installed.cfg", []byte("first")) {
+		t.Errorf("Modified(%q) = true after replay, want false", "installed.cfg")
+	}
+}
+
+func TestManifestUninstall(t *testing.T) {
+	root := tempDir(t)
+	defer os.RemoveAll(root)
+
+	manifest := openManifest(t, root)
+
+	install(t, manifest, "removed/a.cfg", "a")
+	install(t, manifest, "kept.cfg", "kept")
+	install(t, manifest, "missing.cfg", "missing")
+
+	writeFile(t, root, "restored.cfg", "original")
+	install(t, manifest, "restored.cfg", "restored")
+
+	writeFile(t, root, "restored-missing/b.cfg", "original b")
+	install(t, manifest, "restored-missing/b.cfg", "b")
+
+	if err := manifest.Save(); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	writeFile(t, root, "kept.cfg", "modified")
+	os.Remove(filepath.Join(root, "missing.cfg"))
+	os.RemoveAll(filepath.Join(root, "restored-missing"))
+
+	results, err := manifest.Uninstall()
+	if err != nil {
+		t.Fatalf("Uninstall returned error: %v", err)
+	}
+
+	want := map[string]Action{
+		"removed/a.cfg":          ActionRemoved,
+		"kept.cfg":               ActionKept,
+		"missing.cfg":            ActionMissing,
+		"restored.cfg":           ActionRestored,
+		"restored-missing/b.cfg": ActionRestored,
+	}
+
+	if len(results) != len(want) {
+		t.Errorf("Uninstall returned %d results, want %d", len(results), len(want))
+	}
+
+	for _, result := range results {
+		if result.Action != want[result.File.Path] {
+			t.Errorf("Uninstall of %q = %s, want %s", result.File.Path, result.Action, want[result.File.Path])
+		}
+	}
+
+	assertFile(t, filepath.Join(root, "kept.cfg"), "modified")
+	assertFile(t, filepath.Join(root, "restored.cfg"), "original")
+	assertFile(t, filepath.Join(root, "restored-missing", "b.cfg"), "original b")
+
+	if _, err := os.Stat(filepath.Join(root, "removed")); !os.IsNotExist(err) {
+		t.Errorf("emptied directory exists after Uninstall, with error %v", err)
+	}
+
+	// Only the modified file is left to track
+	if files := openManifest(t, root).Files(); len(files) != 1 || files[0].Path != "kept.cfg" {
+		t.Errorf("Files() after Uninstall = %+v, want only kept.cfg", files)
+	}
+}
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "install-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func openManifest(t *testing.T, root string) *Manifest {
+	t.Helper()
+
+	manifest, err := OpenManifest(root)
+	if err != nil {
+		t.Fatalf("OpenManifest returned error: %v", err)
+	}
+
+	return manifest
+}
+
+// install installs the given content to the file at the given path, relative
+// to the install directory of the given manifest.
+func install(t *testing.T, manifest *Manifest, path string, content string) {
+	t.Helper()
+
+	original, err := ioutil.ReadFile(manifest.filePath(path))
+	exists := err == nil
+
+	if _, err := manifest.Begin(path, original, exists, []byte(content)); err != nil {
+		t.Fatalf("Begin(%q) returned error: %v", path, err)
+	}
+
+	writeFile(t, manifest.Root(), path, content)
+}
+
+func writeFile(t *testing.T, root string, path string, content string) {
+	t.Helper()
+
+	path = filepath.Join(root, filepath.FromSlash(path))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertFile(t *testing.T, path string, want string) {
+	t.Helper()
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("reading %s: %v", path, err)
+		return
+	}
+
+	if string(content) != want {
+		t.Errorf("%s contains %q, want %q", path, content, want)
+	}
+}
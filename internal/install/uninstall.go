@@ -0,0 +1,198 @@
+// Copyright © Trevor N. Suarez (Rican7)
+
+package install
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Action defines what was done with an installed file, to uninstall it.
+type Action int
+
+// Actions of an uninstall.
+const (
+	// The file was removed.
+	ActionRemoved Action = iota
+
+	// The file was replaced by the original file that it had replaced.
+	ActionRestored
+
+	// The file was modified since it was installed, so it was left alone,
+	// along with any backup of the original file.
+	ActionKept
+
+	// The file was already removed, and there was no original file to
+	// restore.
+	ActionMissing
+)
+
+var actionNames = map[Action]string{
+	ActionRemoved:  "removed",
+	ActionRestored: "restored",
+	ActionKept:     "kept (modified since installed)",
+	ActionMissing:  "missing",
+}
+
+func (a Action) String() string {
+	return actionNames[a]
+}
+
+// Result defines the result of the uninstall of an installed file.
+type Result struct {
+	File   File
+	Action Action
+}
+
+// Uninstall removes the installed files, restoring the original files that
+// they replaced, and leaving alone any files that were modified since they
+// were installed.
+//
+// The manifest is saved with the files that were left alone, or removed along
+// with the backups if there are none.
+func (m *Manifest) Uninstall() ([]Result, error) {
+	var results []Result
+
+	for _, file := range m.Files() {
+		action, err := m.uninstall(file)
+		if err != nil {
+			// Save what was done, so that it isn't attempted again
+			m.Save()
+
+			return results, err
+		}
+
+		results = append(results, Result{File: file, Action: action})
+	}
+
+	return results, m.Save()
+}
+
+// UninstallFile uninstalls the installed file at the given path, relative to
+// the install directory, like Uninstall, for a file that's no longer
+// generated.
+func (m *Manifest) UninstallFile(path string) (Action, error) {
+	key := filepath.ToSlash(path)
+
+	m.mutex.Lock()
+	file, ok := m.files[key]
+	m.mutex.Unlock()
+
+	if !ok {
+		return 0, fmt.Errorf("uninstalling %s: not installed", path)
+	}
+
+	return m.uninstall(file)
+}
+
+func (m *Manifest) uninstall(file File) (Action, error) {
+	path := m.filePath(file.Path)
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		// Restore the original file in its place, as nothing replaces it now
+		if file.Backup {
+			return m.restore(file, path)
+		}
+
+		// Without a backup there's nothing left to track
+		if err := m.forget(file.Path); err != nil {
+			return 0, err
+		}
+
+		return ActionMissing, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if hash(content) != file.SHA256 {
+		return ActionKept, nil
+	}
+
+	if file.Backup {
+		return m.restore(file, path)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return 0, err
+	}
+
+	if err := m.forget(file.Path); err != nil {
+		return 0, err
+	}
+
+	return ActionRemoved, removeEmptyParents(m.root, filepath.Dir(path))
+}
+
+// restore restores the backup of the original file of the given installed file
+// to the given path, and forgets the installed file.
+func (m *Manifest) restore(file File, path string) (Action, error) {
+	// The directory may have been removed along with the installed file
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return 0, err
+	}
+
+	if err := os.Rename(m.backupPath(file.Path), path); err != nil {
+		return 0, err
+	}
+
+	if err := m.forget(file.Path); err != nil {
+		return 0, err
+	}
+
+	return ActionRestored, nil
+}
+
+// removeEmptyParents removes the given directory, and then each of its parent
+// directories, while they're empty, stopping at the given root directory.
+func removeEmptyParents(root string, dir string) error {
+	root = filepath.Clean(root)
+
+	for dir = filepath.Clean(dir); strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+
+		if len(entries) > 0 {
+			return nil
+		}
+
+		if err := os.Remove(dir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeEmptyTree removes the given directory, if it contains nothing but empty
+// directories, and otherwise removes only the empty directories within it.
+func removeEmptyTree(dir string) error {
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			if err := removeEmptyTree(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Check again, now that any empty directories are removed
+	if entries, err = ioutil.ReadDir(dir); err != nil || len(entries) > 0 {
+		return err
+	}
+
+	return os.Remove(dir)
+}